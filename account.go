@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// activationBaseDelay is how long WaitForActivation waits
+	// before its first poll if Config.RetryTime isn't set.
+	activationBaseDelay = time.Second
+
+	// activationMaxDelay is the longest WaitForActivation waits in between polls.
+	activationMaxDelay = time.Minute
+)
+
+var ErrorAccountPendingApproval = errors.New("account is waiting to be approved by the server's admin")
+
+// errAccountPendingApproval is the APIError.Err of calls our server forbids while our
+// account is pending. It matches both ErrorAccountPendingApproval and ErrorStatusCodeNotOK.
+var errAccountPendingApproval = fmt.Errorf("%w: %w", ErrorAccountPendingApproval, ErrorStatusCodeNotOK)
+
+// AccountState is what we know about our account on the server,
+// from the responses to our calls. See Client.AccountState().
+type AccountState int32
+
+const (
+	AccountStateUnknown  AccountState = iota // We haven't called Login, or any other endpoint needing an account, yet
+	AccountStatePending                      // Login created our account, but the server is waiting for an admin to approve it
+	AccountStateActive                       // Our account can be used
+	AccountStateHidden                       // Our account was active, but the server now forbids it (eg. an admin hid or deactivated it)
+	AccountStateRejected                     // Our account was pending, but the server no longer knows it (eg. an admin rejected it)
+)
+
+func (s AccountState) String() string {
+	switch s {
+	case AccountStateUnknown:
+		return "unknown"
+	case AccountStatePending:
+		return "pending"
+	case AccountStateActive:
+		return "active"
+	case AccountStateHidden:
+		return "hidden"
+	case AccountStateRejected:
+		return "rejected"
+	}
+
+	return "invalid"
+}
+
+// AccountState returns the state of our account on the server, as of our last call.
+//
+// A call to an endpoint needing an account (eg. GetAddressInfo)
+// while our account is pending returns ErrorAccountPendingApproval.
+func (c *Client) AccountState() AccountState {
+	return AccountState(c.accountState.Load())
+}
+
+// accountEndpoint reports whether calls to 'e' need our account to be active
+func accountEndpoint(e Endpoint) bool {
+	switch e {
+	case EndpointLogin,
+		EndpointGetAddressInfo,
+		EndpointGetAddressTxs,
+		EndpointGetUnspentOuts,
+		EndpointImportRequest,
+		EndpointGetSubaddrs,
+		EndpointUpsertSubaddrs,
+		EndpointProvisionSubaddrs:
+		return true
+	}
+
+	return false
+}
+
+// accountForbidden reports whether HTTP 403 from 'e' means our account
+// is forbidden. /upsert_subaddrs and /provision_subaddrs also send it
+// when we'd have more subaddresses than the server allows.
+func accountForbidden(e Endpoint) bool {
+	switch e {
+	case EndpointUpsertSubaddrs, EndpointProvisionSubaddrs:
+		return false
+	}
+
+	return accountEndpoint(e)
+}
+
+// pendingApproval reports whether 'resp', the final response
+// to call 'cl', means our account is waiting to be approved
+func (c *Client) pendingApproval(cl *Call, resp *http.Response) bool {
+	return resp.StatusCode == http.StatusForbidden &&
+		cl.Endpoint != EndpointLogin &&
+		accountEndpoint(cl.Endpoint) &&
+		c.AccountState() == AccountStatePending
+}
+
+// trackAccount updates our account's state with the outcome 'err'
+// of a call to 'endpoint'. Logins are tracked by LoginContext.
+func (c *Client) trackAccount(endpoint Endpoint, err error) error {
+	if endpoint == EndpointLogin || !accountEndpoint(endpoint) {
+		return err
+	}
+
+	if err == nil {
+		c.accountState.Store(int32(AccountStateActive))
+
+		return nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden && accountForbidden(endpoint) {
+		c.accountState.CompareAndSwap(int32(AccountStateActive), int32(AccountStateHidden))
+	}
+
+	return err
+}
+
+// trackLogin updates our account's state with the
+// outcome 'err' of a call to /login with 'request'.
+func (c *Client) trackLogin(request *LoginRequest, response *LoginResponse, err error) {
+	if err == nil {
+		// Servers keep saying we're new until our account is approved
+		if response.NewAddress {
+			c.accountState.Store(int32(AccountStatePending))
+		} else {
+			c.accountState.Store(int32(AccountStateActive))
+		}
+
+		return
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		return
+	}
+
+	// Servers also forbid logging in to accounts they don't know without
+	// CreateAccount, but pending accounts can be created, so ours must be gone
+	if request.CreateAccount {
+		c.accountState.CompareAndSwap(int32(AccountStatePending), int32(AccountStateRejected))
+	}
+}
+
+// WaitForActivation blocks until our account can be used, calling
+// Login again (with the LoginRequest from our last call to it) while
+// our account is pending. Config.RetryTime (or a second, if it isn't
+// set) is waited before the first poll, doubled after each poll up to
+// a minute.
+//
+// It returns the error from Login if our account isn't pending or
+// active (see AccountState()), or ctx.Err() if 'ctx' is done first.
+func (c *Client) WaitForActivation(ctx context.Context) error {
+	wait := c.retryTime
+	if wait <= 0 {
+		wait = activationBaseDelay
+	}
+
+	for {
+		request := LoginRequest{}
+		if last := c.lastLogin.Load(); last != nil {
+			request = *last
+		}
+
+		_, err := c.LoginContext(ctx, &request)
+		if err != nil {
+			return err
+		}
+
+		if c.AccountState() == AccountStateActive {
+			return nil
+		}
+
+		err = sleep(ctx, wait)
+		if err != nil {
+			return err
+		}
+
+		wait *= 2
+		if wait > activationMaxDelay {
+			wait = activationMaxDelay
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAccountState(t *testing.T) {
+	var mu sync.Mutex
+
+	state := AccountStatePending
+	logins := 0
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch Endpoint(r.URL.Path) {
+		case EndpointLogin:
+			logins++
+
+			// The account is approved after being polled twice
+			if state == AccountStatePending && logins > 3 {
+				state = AccountStateActive
+			}
+
+			switch state {
+			case AccountStatePending:
+				_, _ = w.Write([]byte(`{"new_address":true}`))
+			case AccountStateActive:
+				_, _ = w.Write([]byte(`{"new_address":false}`))
+			default:
+				w.WriteHeader(http.StatusForbidden)
+			}
+		case EndpointGetAddressInfo:
+			if state != AccountStateActive {
+				w.WriteHeader(http.StatusForbidden)
+
+				return
+			}
+
+			err := json.NewEncoder(w).Encode(GetAddressInfoResponse{})
+			if err != nil {
+				t.Error("failed to marshal our response")
+			}
+		default:
+			t.Error("the client called ", r.URL.Path)
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	var intercepted error
+
+	client := &Client{
+		address: "xmr_address",
+		client:  &http.Client{},
+		interceptors: []Interceptor{func(ctx context.Context, call *Call, next Invoker) error {
+			intercepted = next(ctx, call)
+
+			return intercepted
+		}},
+		retryTime: time.Millisecond,
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	if client.AccountState() != AccountStateUnknown {
+		t.Error("a new client's account state was ", client.AccountState())
+	}
+
+	_, err := client.Login(&LoginRequest{CreateAccount: true})
+	if err != nil {
+		t.Fatal("Login() returned the error: ", err)
+	}
+
+	if client.AccountState() != AccountStatePending {
+		t.Error("the account state after a new login was ", client.AccountState())
+	}
+
+	_, err = client.GetAddressInfo()
+	if !errors.Is(err, ErrorAccountPendingApproval) || !errors.Is(err, ErrorStatusCodeNotOK) {
+		t.Error("GetAddressInfo() returned the error: ", err)
+	}
+
+	if intercepted != err {
+		t.Errorf("our interceptor saw the error %v, but GetAddressInfo() returned %v", intercepted, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = client.WaitForActivation(ctx)
+	if err != nil {
+		t.Fatal("WaitForActivation() returned the error: ", err)
+	}
+
+	if client.AccountState() != AccountStateActive || logins != 4 {
+		t.Errorf("the account state after %d logins was %v", logins, client.AccountState())
+	}
+
+	_, err = client.GetAddressInfo()
+	if err != nil {
+		t.Fatal("GetAddressInfo() returned the error: ", err)
+	}
+
+	mu.Lock()
+	state = AccountStateHidden
+	mu.Unlock()
+
+	_, err = client.GetAddressInfo()
+	if !errors.Is(err, ErrorStatusCodeNotOK) || client.AccountState() != AccountStateHidden {
+		t.Errorf("GetAddressInfo() returned the error %v with the account state %v", err, client.AccountState())
+	}
+}
+
+func TestWaitForActivationRejected(t *testing.T) {
+	logins := 0
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		logins++
+
+		// The admin rejects the account after its first login
+		if logins > 1 {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"new_address":true}`))
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		retryTime: time.Millisecond,
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	_, err := client.Login(&LoginRequest{CreateAccount: true})
+	if err != nil {
+		t.Fatal("Login() returned the error: ", err)
+	}
+
+	err = client.WaitForActivation(context.Background())
+	if !errors.Is(err, ErrorStatusCodeNotOK) {
+		t.Error("WaitForActivation() returned the error: ", err)
+	}
+
+	if client.AccountState() != AccountStateRejected {
+		t.Error("the account state was ", client.AccountState())
+	}
+}
+
+func TestAccountStateForbidden(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch Endpoint(r.URL.Path) {
+		case EndpointLogin:
+			var request LoginRequest
+
+			err := json.NewDecoder(r.Body).Decode(&request)
+			if err != nil {
+				t.Error("failed to unmarshal the client's request")
+			}
+
+			// Accounts can't be logged in to without CreateAccount until they're approved
+			if !request.CreateAccount {
+				w.WriteHeader(http.StatusForbidden)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"new_address":true}`))
+		case EndpointUpsertSubaddrs:
+			// The account would have too many subaddresses
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	_, err := client.Login(&LoginRequest{CreateAccount: true})
+	if err != nil {
+		t.Fatal("Login() returned the error: ", err)
+	}
+
+	_, err = client.Login(&LoginRequest{})
+	if !errors.Is(err, ErrorStatusCodeNotOK) || client.AccountState() != AccountStatePending {
+		t.Errorf("Login() returned the error %v with the account state %v", err, client.AccountState())
+	}
+
+	_, err = client.GetAddressInfo()
+	if err != nil || client.AccountState() != AccountStateActive {
+		t.Fatalf("GetAddressInfo() returned the error %v with the account state %v", err, client.AccountState())
+	}
+
+	_, err = client.UpsertSubaddrs(&UpsertSubaddrsRequest{})
+	if !errors.Is(err, ErrorStatusCodeNotOK) || client.AccountState() != AccountStateActive {
+		t.Errorf("UpsertSubaddrs() returned the error %v with the account state %v", err, client.AccountState())
+	}
+}
@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	ErrorAddressInvalid   = errors.New("XMR address is invalid")
+	ErrorPaymentIDInvalid = errors.New("payment ID isn't 8 bytes of hex encoded binary")
+)
+
+const (
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	// base58BlockSize is the number of bytes Monero's base58 encodes at a time
+	base58BlockSize = 8
+
+	// addressChecksumSize is the length of an address's checksum, in bytes
+	addressChecksumSize = 4
+
+	// paymentIDSize is the length of an integrated address's payment ID, in bytes
+	paymentIDSize = 8
+)
+
+// base58EncodedSizes maps the size of a block of bytes to the size of its encoding
+var base58EncodedSizes = [base58BlockSize + 1]int{0, 2, 3, 5, 6, 7, 9, 10, 11}
+
+// addressPrefix describes an address type on one of Monero's networks
+type addressPrefix struct {
+	network    string // eg. "main"
+	integrated bool
+	subaddress bool
+}
+
+// addressPrefixes maps each address prefix to the type of address it's for
+var addressPrefixes = map[uint64]addressPrefix{
+	18: {network: "main"},
+	19: {network: "main", integrated: true},
+	42: {network: "main", subaddress: true},
+	53: {network: "test"},
+	54: {network: "test", integrated: true},
+	63: {network: "test", subaddress: true},
+	24: {network: "stage"},
+	25: {network: "stage", integrated: true},
+	36: {network: "stage", subaddress: true},
+}
+
+// IntegratedAddress combines the standard XMR address 'address' with
+// 'paymentID' (8 bytes of hex encoded binary) into an integrated address.
+func IntegratedAddress(address string, paymentID string) (string, error) {
+	prefix, keys, err := decodeAddress(address)
+	if err != nil {
+		return "", err
+	}
+
+	if addressPrefixes[prefix].integrated || addressPrefixes[prefix].subaddress {
+		return "", ErrorAddressInvalid
+	}
+
+	id, err := hex.DecodeString(paymentID)
+	if err != nil || len(id) != paymentIDSize {
+		return "", ErrorPaymentIDInvalid
+	}
+
+	// Each network's integrated prefix is one more than its standard prefix
+	return encodeAddress(prefix+1, append(keys, id...)), nil
+}
+
+// decodeAddress returns the prefix of 'address', and
+// the data (keys, and payment ID if it has one) after it.
+func decodeAddress(address string) (uint64, []byte, error) {
+	b, err := base58Decode(address)
+	if err != nil || len(b) <= addressChecksumSize {
+		return 0, nil, ErrorAddressInvalid
+	}
+
+	data, checksum := b[:len(b)-addressChecksumSize], b[len(b)-addressChecksumSize:]
+	if !bytes.Equal(keccak256(data)[:addressChecksumSize], checksum) {
+		return 0, nil, ErrorAddressInvalid
+	}
+
+	prefix, n := uvarint(data)
+	if n <= 0 {
+		return 0, nil, ErrorAddressInvalid
+	}
+
+	info, ok := addressPrefixes[prefix]
+	if !ok {
+		return 0, nil, ErrorAddressInvalid
+	}
+
+	size := 64
+	if info.integrated {
+		size += paymentIDSize
+	}
+
+	if len(data)-n != size {
+		return 0, nil, ErrorAddressInvalid
+	}
+
+	return prefix, data[n:], nil
+}
+
+// encodeAddress encodes 'data' with 'prefix' and a checksum as an address
+func encodeAddress(prefix uint64, data []byte) string {
+	var b []byte
+
+	for prefix >= 0x80 {
+		b = append(b, byte(prefix)|0x80)
+		prefix >>= 7
+	}
+
+	b = append(b, byte(prefix))
+	b = append(b, data...)
+	b = append(b, keccak256(b)[:addressChecksumSize]...)
+
+	return base58Encode(b)
+}
+
+// uvarint decodes the varint at the start of 'b' like binary.Uvarint
+func uvarint(b []byte) (uint64, int) {
+	var v uint64
+
+	for i, c := range b {
+		if i == 9 {
+			return 0, -1
+		}
+
+		v |= uint64(c&0x7f) << (7 * i)
+
+		if c < 0x80 {
+			return v, i + 1
+		}
+	}
+
+	return 0, 0
+}
+
+func keccak256(b []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(b)
+
+	return h.Sum(nil)
+}
+
+// base58Encode encodes 'b' with Monero's base58, which encodes
+// blocks of 8 bytes at a time so every encoding has the same length.
+func base58Encode(b []byte) string {
+	var sb strings.Builder
+
+	for len(b) > 0 {
+		n := min(len(b), base58BlockSize)
+
+		num := new(big.Int).SetBytes(b[:n])
+		block := make([]byte, base58EncodedSizes[n])
+
+		for i := len(block) - 1; i >= 0; i-- {
+			mod := new(big.Int)
+			num.DivMod(num, big.NewInt(58), mod)
+			block[i] = base58Alphabet[mod.Int64()]
+		}
+
+		sb.Write(block)
+		b = b[n:]
+	}
+
+	return sb.String()
+}
+
+// base58Decode decodes 's', which was encoded with base58Encode()
+func base58Decode(s string) ([]byte, error) {
+	var b []byte
+
+	for len(s) > 0 {
+		n := min(len(s), base58EncodedSizes[base58BlockSize])
+
+		size := -1
+		for i, encoded := range base58EncodedSizes {
+			if encoded == n {
+				size = i
+			}
+		}
+
+		if size < 0 {
+			return nil, ErrorAddressInvalid
+		}
+
+		num := new(big.Int)
+		for _, c := range []byte(s[:n]) {
+			i := strings.IndexByte(base58Alphabet, c)
+			if i < 0 {
+				return nil, ErrorAddressInvalid
+			}
+
+			num.Mul(num, big.NewInt(58))
+			num.Add(num, big.NewInt(int64(i)))
+		}
+
+		if num.BitLen() > size*8 {
+			return nil, ErrorAddressInvalid
+		}
+
+		b = append(b, num.FillBytes(make([]byte, size))...)
+		s = s[n:]
+	}
+
+	return b, nil
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"bytes"
+	"testing"
+)
+
+// testAddress is the Monero General Fund's donation address
+const testAddress = "4AdUndXHHZ6cfufTMvppY6JwXNouMBzSkbLYfpAV5Usx3skxNgYeYTRj5UzqtReoS44qo9mtmXCqY45DJ852K5Jv2684Rge"
+
+func TestIntegratedAddress(t *testing.T) {
+	integrated, err := IntegratedAddress(testAddress, "420fa29b2d9a49f5")
+	if err != nil {
+		t.Fatal("IntegratedAddress() returned the error: ", err)
+	}
+
+	if len(integrated) != 106 || integrated[:2] != "4L" {
+		t.Error("IntegratedAddress() returned ", integrated)
+	}
+
+	prefix, data, err := decodeAddress(integrated)
+	if err != nil {
+		t.Fatal("the integrated address couldn't be decoded: ", err)
+	}
+
+	_, keys, _ := decodeAddress(testAddress)
+
+	if prefix != 19 || !bytes.Equal(data[:64], keys) || !bytes.Equal(data[64:], []byte{0x42, 0x0f, 0xa2, 0x9b, 0x2d, 0x9a, 0x49, 0xf5}) {
+		t.Errorf("the integrated address decoded to prefix %d and data %x", prefix, data)
+	}
+
+	tests := []struct {
+		name      string
+		address   string
+		paymentID string
+		err       error
+	}{
+		{"integrated address", integrated, "420fa29b2d9a49f5", ErrorAddressInvalid},
+		{"bad checksum", testAddress[:len(testAddress)-1] + "f", "420fa29b2d9a49f5", ErrorAddressInvalid},
+		{"not base58", "0" + testAddress[1:], "420fa29b2d9a49f5", ErrorAddressInvalid},
+		{"truncated", testAddress[:90], "420fa29b2d9a49f5", ErrorAddressInvalid},
+		{"long payment ID", testAddress, "e8021307f3e7f10a41a712ea7f26d4f9f72f78597011cc5859b11d3eaa97d998", ErrorPaymentIDInvalid},
+		{"payment ID not hex", testAddress, "payment", ErrorPaymentIDInvalid},
+	}
+
+	for _, test := range tests {
+		_, err := IntegratedAddress(test.address, test.paymentID)
+		if err != test.err {
+			t.Errorf("%s: IntegratedAddress() returned the error %v, expected %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestBase58(t *testing.T) {
+	for _, b := range [][]byte{{}, {0}, {0xff}, {0, 0, 0, 0, 0, 0, 0, 0, 1}, bytes.Repeat([]byte{0xff}, 69)} {
+		decoded, err := base58Decode(base58Encode(b))
+		if err != nil || !bytes.Equal(decoded, b) {
+			t.Errorf("%x was decoded to %x, %v", b, decoded, err)
+		}
+	}
+
+	// An 11 character block that overflows 8 bytes
+	_, err := base58Decode("zzzzzzzzzzz")
+	if err != ErrorAddressInvalid {
+		t.Error("base58Decode() returned the error: ", err)
+	}
+}
@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrorAdminRequestEncode = errors.New("failed to encode admin request")
+
+// AccountStatus is the status of an account on a monero-lws server.
+type AccountStatus string
+
+const (
+	AccountActive   AccountStatus = "active"   // The account is scanned
+	AccountInactive AccountStatus = "inactive" // The account isn't scanned, but can be made active again
+	AccountHidden   AccountStatus = "hidden"   // The account isn't scanned or listed to its owner
+)
+
+// RequestType is the type of a request waiting on a monero-lws admin.
+type RequestType string
+
+const (
+	RequestCreate RequestType = "create" // A new account, eg. from a call to Login with CreateAccount set
+	RequestImport RequestType = "import" // A rescan of an account's history, eg. from a call to ImportRequest
+)
+
+// AdminClient calls the admin API of a monero-lws server (see
+// monero-lws-daemon's --admin-rest-server option) to manage its
+// accounts. It shares its transport, retries and errors with Client.
+type AdminClient struct {
+	client *Client
+	auth   string
+}
+
+// NewAdminClient creates a new admin client using 'cfg', authenticated
+// with the admin key 'auth' (which can be empty if the server doesn't
+// require one). cfg.Address and cfg.ViewKey are ignored, and requests
+// are always sent as JSON.
+func NewAdminClient(cfg Config, auth string) (*AdminClient, error) {
+	if cfg.ServerURL == "" && len(cfg.ServerURLs) == 0 {
+		newLogger(cfg.Logger).Error("no admin server URL was passed to NewAdminClient() call")
+
+		return nil, ErrorBadConfig
+	}
+
+	cfg.Address = ""
+	cfg.ViewKey = ""
+	cfg.Encoding = EncodingJSON
+
+	c, err := newClient(cfg, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AdminClient{client: c, auth: auth}, nil
+}
+
+// adminRequest is the envelope every admin request is sent in
+type adminRequest struct {
+	Auth   string      `json:"auth,omitempty"`
+	Params interface{} `json:"params"`
+}
+
+// post posts 'params' to 'endpoint' on our admin server,
+// see Client.post() for details on how errors are returned.
+func (a *AdminClient) post(ctx context.Context, endpoint Endpoint, params interface{}, response interface{}) error {
+	return a.client.post(ctx, endpoint, &adminRequest{Auth: a.auth, Params: params}, response, ErrorAdminRequestEncode)
+}
+
+// AdminAccount is an account on a monero-lws server.
+type AdminAccount struct {
+	Address    string `json:"address"`
+	ScanHeight uint64 `json:"scan_height"`
+	AccessTime uint64 `json:"access_time"` // When the account was last used, in unix time
+}
+
+type ListAccountsResponse struct {
+	Active   []AdminAccount `json:"active"`
+	Inactive []AdminAccount `json:"inactive"`
+	Hidden   []AdminAccount `json:"hidden"`
+}
+
+// ListAccounts lists every account on the server, by status.
+func (a *AdminClient) ListAccounts() (*ListAccountsResponse, error) {
+	return a.ListAccountsContext(context.Background())
+}
+
+// ListAccountsContext is like ListAccounts but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (a *AdminClient) ListAccountsContext(ctx context.Context) (*ListAccountsResponse, error) {
+	var response = &ListAccountsResponse{}
+
+	err := a.post(ctx, EndpointListAccounts, &struct{}{}, response)
+	if err != nil {
+		return &ListAccountsResponse{}, err
+	}
+
+	return response, nil
+}
+
+// PendingRequest is a request waiting on a monero-lws admin to accept or reject it.
+type PendingRequest struct {
+	Address     string `json:"address"`
+	StartHeight uint64 `json:"start_height"` // The height the account will be scanned from
+}
+
+type ListRequestsResponse struct {
+	Create []PendingRequest `json:"create"`
+	Import []PendingRequest `json:"import"`
+}
+
+// ListRequests lists the requests waiting to be accepted or rejected, by type.
+func (a *AdminClient) ListRequests() (*ListRequestsResponse, error) {
+	return a.ListRequestsContext(context.Background())
+}
+
+// ListRequestsContext is like ListRequests but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (a *AdminClient) ListRequestsContext(ctx context.Context) (*ListRequestsResponse, error) {
+	var response = &ListRequestsResponse{}
+
+	err := a.post(ctx, EndpointListRequests, &struct{}{}, response)
+	if err != nil {
+		return &ListRequestsResponse{}, err
+	}
+
+	return response, nil
+}
+
+// UpdatedResponse lists the accounts an admin request changed.
+type UpdatedResponse struct {
+	Updated []string `json:"updated"`
+}
+
+type requestsParams struct {
+	Type      RequestType `json:"type"`
+	Addresses []string    `json:"addresses"`
+}
+
+// AcceptRequests accepts the 'requestType' requests for 'addresses'.
+func (a *AdminClient) AcceptRequests(requestType RequestType, addresses []string) (*UpdatedResponse, error) {
+	return a.AcceptRequestsContext(context.Background(), requestType, addresses)
+}
+
+// AcceptRequestsContext is like AcceptRequests but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (a *AdminClient) AcceptRequestsContext(ctx context.Context, requestType RequestType, addresses []string) (*UpdatedResponse, error) {
+	var response = &UpdatedResponse{}
+
+	err := a.post(ctx, EndpointAcceptRequests, &requestsParams{Type: requestType, Addresses: addresses}, response)
+	if err != nil {
+		return &UpdatedResponse{}, err
+	}
+
+	return response, nil
+}
+
+// RejectRequests rejects the 'requestType' requests for 'addresses'.
+func (a *AdminClient) RejectRequests(requestType RequestType, addresses []string) (*UpdatedResponse, error) {
+	return a.RejectRequestsContext(context.Background(), requestType, addresses)
+}
+
+// RejectRequestsContext is like RejectRequests but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (a *AdminClient) RejectRequestsContext(ctx context.Context, requestType RequestType, addresses []string) (*UpdatedResponse, error) {
+	var response = &UpdatedResponse{}
+
+	err := a.post(ctx, EndpointRejectRequests, &requestsParams{Type: requestType, Addresses: addresses}, response)
+	if err != nil {
+		return &UpdatedResponse{}, err
+	}
+
+	return response, nil
+}
+
+type addAccountParams struct {
+	Address string `json:"address"`
+	ViewKey string `json:"key"`
+}
+
+// AddAccount adds the account with XMR address 'address' and private
+// view key 'viewKey' to the server, without waiting for a request.
+func (a *AdminClient) AddAccount(address string, viewKey string) error {
+	return a.AddAccountContext(context.Background(), address, viewKey)
+}
+
+// AddAccountContext is like AddAccount but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (a *AdminClient) AddAccountContext(ctx context.Context, address string, viewKey string) error {
+	return a.post(ctx, EndpointAddAccount, &addAccountParams{Address: address, ViewKey: viewKey}, nil)
+}
+
+type modifyAccountStatusParams struct {
+	Status    AccountStatus `json:"status"`
+	Addresses []string      `json:"addresses"`
+}
+
+// ModifyAccountStatus changes the status of the accounts in 'addresses' to 'status'.
+func (a *AdminClient) ModifyAccountStatus(status AccountStatus, addresses []string) (*UpdatedResponse, error) {
+	return a.ModifyAccountStatusContext(context.Background(), status, addresses)
+}
+
+// ModifyAccountStatusContext is like ModifyAccountStatus but uses 'ctx' to
+// cancel the request or to stop waiting in between retries.
+func (a *AdminClient) ModifyAccountStatusContext(ctx context.Context, status AccountStatus, addresses []string) (*UpdatedResponse, error) {
+	var response = &UpdatedResponse{}
+
+	err := a.post(ctx, EndpointModifyAccountStatus, &modifyAccountStatusParams{Status: status, Addresses: addresses}, response)
+	if err != nil {
+		return &UpdatedResponse{}, err
+	}
+
+	return response, nil
+}
+
+type rescanParams struct {
+	Height    uint64   `json:"height"`
+	Addresses []string `json:"addresses"`
+}
+
+// Rescan rescans the accounts in 'addresses' from block 'height'.
+func (a *AdminClient) Rescan(height uint64, addresses []string) (*UpdatedResponse, error) {
+	return a.RescanContext(context.Background(), height, addresses)
+}
+
+// RescanContext is like Rescan but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (a *AdminClient) RescanContext(ctx context.Context, height uint64, addresses []string) (*UpdatedResponse, error) {
+	var response = &UpdatedResponse{}
+
+	err := a.post(ctx, EndpointRescan, &rescanParams{Height: height, Addresses: addresses}, response)
+	if err != nil {
+		return &UpdatedResponse{}, err
+	}
+
+	return response, nil
+}
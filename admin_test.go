@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAdminClient(t *testing.T) {
+	tryCount := 1 //Number of times to send HTTP Service Unavailable
+
+	const auth = "admin_auth_key"
+
+	params := map[Endpoint]string{}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Auth   string          `json:"auth"`
+			Params json.RawMessage `json:"params"`
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Error("AdminClient made an invalid request: ", err)
+		}
+
+		if req.Auth != auth {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		if tryCount != 0 {
+			tryCount--
+
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		params[Endpoint(r.URL.Path)] = string(req.Params)
+
+		var response string
+
+		switch Endpoint(r.URL.Path) {
+		case EndpointListAccounts:
+			response = `{"active":[{"address":"xmr_address","scan_height":3222360,"access_time":1718000000}],"hidden":[{"address":"xmr_hidden","scan_height":10,"access_time":0}]}`
+		case EndpointListRequests:
+			response = `{"create":[{"address":"xmr_new","start_height":3222000}]}`
+		case EndpointAcceptRequests, EndpointRejectRequests, EndpointModifyAccountStatus, EndpointRescan:
+			response = `{"updated":["xmr_new"]}`
+		case EndpointAddAccount:
+			// monero-lws sends an empty body
+		default:
+			t.Error("AdminClient called ", r.URL.Path)
+		}
+
+		_, err = w.Write([]byte(response))
+		if err != nil {
+			t.Error("failed to write our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	admin, err := NewAdminClient(Config{RetryCount: tryCount, ServerURL: ts.URL}, auth)
+	if err != nil {
+		t.Fatal("NewAdminClient() returned the error: ", err)
+	}
+
+	accounts, err := admin.ListAccounts()
+	if err != nil {
+		t.Fatal("ListAccounts() returned the error: ", err)
+	}
+
+	expectedAccounts := ListAccountsResponse{
+		Active: []AdminAccount{{Address: "xmr_address", ScanHeight: 3222360, AccessTime: 1718000000}},
+		Hidden: []AdminAccount{{Address: "xmr_hidden", ScanHeight: 10}},
+	}
+
+	if !reflect.DeepEqual(*accounts, expectedAccounts) {
+		t.Error("ListAccounts() returned: ", accounts)
+	}
+
+	requests, err := admin.ListRequests()
+	if err != nil {
+		t.Fatal("ListRequests() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(*requests, ListRequestsResponse{Create: []PendingRequest{{Address: "xmr_new", StartHeight: 3222000}}}) {
+		t.Error("ListRequests() returned: ", requests)
+	}
+
+	updated := []string{"xmr_new"}
+
+	calls := []struct {
+		name string
+		call func() (*UpdatedResponse, error)
+	}{
+		{"AcceptRequests", func() (*UpdatedResponse, error) { return admin.AcceptRequests(RequestCreate, updated) }},
+		{"RejectRequests", func() (*UpdatedResponse, error) { return admin.RejectRequests(RequestImport, updated) }},
+		{"ModifyAccountStatus", func() (*UpdatedResponse, error) { return admin.ModifyAccountStatus(AccountInactive, updated) }},
+		{"Rescan", func() (*UpdatedResponse, error) { return admin.Rescan(3000000, updated) }},
+	}
+
+	for _, call := range calls {
+		resp, err := call.call()
+		if err != nil {
+			t.Errorf("%s() returned the error: %v", call.name, err)
+
+			continue
+		}
+
+		if !reflect.DeepEqual(resp.Updated, updated) {
+			t.Errorf("%s() returned: %v", call.name, resp)
+		}
+	}
+
+	err = admin.AddAccount("xmr_added", "xmr_view_key")
+	if err != nil {
+		t.Fatal("AddAccount() returned the error: ", err)
+	}
+
+	expectedParams := map[Endpoint]string{
+		EndpointListAccounts:        `{}`,
+		EndpointListRequests:        `{}`,
+		EndpointAcceptRequests:      `{"type":"create","addresses":["xmr_new"]}`,
+		EndpointRejectRequests:      `{"type":"import","addresses":["xmr_new"]}`,
+		EndpointModifyAccountStatus: `{"status":"inactive","addresses":["xmr_new"]}`,
+		EndpointRescan:              `{"height":3000000,"addresses":["xmr_new"]}`,
+		EndpointAddAccount:          `{"address":"xmr_added","key":"xmr_view_key"}`,
+	}
+
+	if !reflect.DeepEqual(params, expectedParams) {
+		t.Error("AdminClient sent the params: ", params)
+	}
+}
+
+func TestAdminClientBadAuth(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	admin, err := NewAdminClient(Config{ServerURL: ts.URL}, "wrong_key")
+	if err != nil {
+		t.Fatal("NewAdminClient() returned the error: ", err)
+	}
+
+	_, err = admin.ListAccounts()
+
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusForbidden || apiErr.Endpoint != EndpointListAccounts {
+		t.Error("ListAccounts() returned the error: ", err)
+	}
+
+	_, err = NewAdminClient(Config{}, "")
+	if err != ErrorBadConfig {
+		t.Error("NewAdminClient() accepted a config without a server URL: ", err)
+	}
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrorAmountOverflow = errors.New("amount doesn't fit in a uint64 of piconero")
+	ErrorAmountInvalid  = errors.New("amount isn't a valid number of XMR or piconero")
+)
+
+// Amount is an amount of XMR, in piconero (atomic units).
+//
+// It's encoded in JSON as a quoted number of piconero, like the light
+// wallet API's amounts, and decoded from quoted or bare numbers.
+type Amount uint64
+
+const (
+	Piconero Amount = 1
+	XMR      Amount = 1e12
+
+	// xmrDecimals is the number of decimal places XMR has
+	xmrDecimals = 12
+)
+
+// ParseAmount parses 's', a decimal number of piconero (eg. "1234567890123").
+func ParseAmount(s string) (Amount, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, ErrorAmountOverflow
+	} else if err != nil {
+		return 0, ErrorAmountInvalid
+	}
+
+	return Amount(n), nil
+}
+
+// ParseXMR parses 's', a decimal number of XMR with up to 12 decimal places (eg. "1.234567890123").
+func ParseXMR(s string) (Amount, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+
+	if whole == "" && frac == "" || len(frac) > xmrDecimals || !digits(whole) || !digits(frac) {
+		return 0, ErrorAmountInvalid
+	}
+
+	var w uint64
+	if whole != "" {
+		var err error
+
+		w, err = strconv.ParseUint(whole, 10, 64)
+		if err != nil {
+			return 0, ErrorAmountOverflow
+		}
+	}
+
+	var f uint64
+	if frac != "" {
+		f, _ = strconv.ParseUint(frac+strings.Repeat("0", xmrDecimals-len(frac)), 10, 64)
+	}
+
+	a, err := Amount(w).Mul(uint64(XMR))
+	if err != nil {
+		return 0, err
+	}
+
+	return a.Add(Amount(f))
+}
+
+// digits reports whether 's' only holds the digits 0 to 9
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Add returns a + b, or ErrorAmountOverflow if it doesn't fit in an Amount.
+func (a Amount) Add(b Amount) (Amount, error) {
+	sum, carry := bits.Add64(uint64(a), uint64(b), 0)
+	if carry != 0 {
+		return 0, ErrorAmountOverflow
+	}
+
+	return Amount(sum), nil
+}
+
+// Sub returns a - b, or ErrorAmountOverflow if b is larger than a.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b > a {
+		return 0, ErrorAmountOverflow
+	}
+
+	return a - b, nil
+}
+
+// Mul returns a * n, or ErrorAmountOverflow if it doesn't fit in an Amount.
+func (a Amount) Mul(n uint64) (Amount, error) {
+	hi, lo := bits.Mul64(uint64(a), n)
+	if hi != 0 {
+		return 0, ErrorAmountOverflow
+	}
+
+	return Amount(lo), nil
+}
+
+// String formats 'a' as a decimal number of XMR (eg. "1.5"), which ParseXMR parses.
+func (a Amount) String() string {
+	s := strconv.FormatUint(uint64(a/XMR), 10)
+
+	frac := strings.TrimRight(strconv.FormatUint(uint64(a%XMR+XMR), 10)[1:], "0")
+	if frac != "" {
+		s += "." + frac
+	}
+
+	return s
+}
+
+// Piconero formats 'a' as a decimal number of piconero, which ParseAmount parses.
+func (a Amount) Piconero() string {
+	return strconv.FormatUint(uint64(a), 10)
+}
+
+// MarshalJSON implements json.Marshaler
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.Piconero())), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	s := string(b)
+
+	if len(b) > 0 && b[0] == '"' {
+		err := json.Unmarshal(b, &s)
+		if err != nil {
+			return err
+		}
+	}
+
+	n, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+
+	*a = n
+
+	return nil
+}
+
+// parseAmountField parses the amount field 's', which is 0 if the server left it out
+func parseAmountField(s string) (Amount, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	return ParseAmount(s)
+}
+
+// LockedFundsAmount returns LockedFunds as an Amount.
+func (r *GetAddressInfoResponse) LockedFundsAmount() (Amount, error) {
+	return parseAmountField(r.LockedFunds)
+}
+
+// TotalReceivedAmount returns TotalReceived as an Amount.
+func (r *GetAddressInfoResponse) TotalReceivedAmount() (Amount, error) {
+	return parseAmountField(r.TotalReceived)
+}
+
+// TotalSentAmount returns TotalSent as an Amount.
+func (r *GetAddressInfoResponse) TotalSentAmount() (Amount, error) {
+	return parseAmountField(r.TotalSent)
+}
+
+// Balance returns TotalReceived - TotalSent, the account's balance including locked funds.
+func (r *GetAddressInfoResponse) Balance() (Amount, error) {
+	received, err := r.TotalReceivedAmount()
+	if err != nil {
+		return 0, err
+	}
+
+	sent, err := r.TotalSentAmount()
+	if err != nil {
+		return 0, err
+	}
+
+	return received.Sub(sent)
+}
+
+// TotalReceivedAmount returns TotalReceived as an Amount.
+func (r *GetAddressTxsResponse) TotalReceivedAmount() (Amount, error) {
+	return parseAmountField(r.TotalReceived)
+}
+
+// TotalReceivedAmount returns TotalReceived as an Amount.
+func (t *Transaction) TotalReceivedAmount() (Amount, error) {
+	return parseAmountField(t.TotalReceived)
+}
+
+// TotalSentAmount returns TotalSent as an Amount.
+func (t *Transaction) TotalSentAmount() (Amount, error) {
+	return parseAmountField(t.TotalSent)
+}
+
+// AmountValue returns Amount as an Amount.
+func (s *Spend) AmountValue() (Amount, error) {
+	return parseAmountField(s.Amount)
+}
+
+// AmountValue returns Amount as an Amount.
+func (o *Output) AmountValue() (Amount, error) {
+	return parseAmountField(o.Amount)
+}
+
+// AmountValue returns Amount as an Amount.
+func (r *GetUnspentOutsResponse) AmountValue() (Amount, error) {
+	return parseAmountField(r.Amount)
+}
+
+// PerByteFeeAmount returns PerByteFee as an Amount.
+func (r *GetUnspentOutsResponse) PerByteFeeAmount() (Amount, error) {
+	return parseAmountField(r.PerByteFee)
+}
+
+// FeeMaskAmount returns FeeMask as an Amount.
+func (r *GetUnspentOutsResponse) FeeMaskAmount() (Amount, error) {
+	return parseAmountField(r.FeeMask)
+}
+
+// AmountValue returns Amount as an Amount.
+func (r *RandomOutputs) AmountValue() (Amount, error) {
+	return parseAmountField(r.Amount)
+}
+
+// ImportFeeAmount returns ImportFee as an Amount.
+func (r *ImportRequestResponse) ImportFeeAmount() (Amount, error) {
+	return parseAmountField(r.ImportFee)
+}
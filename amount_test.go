@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseXMR(t *testing.T) {
+	tests := []struct {
+		s      string
+		amount Amount
+		err    error
+	}{
+		{"1.234567890123", 1234567890123, nil},
+		{"0.05", 50000000000, nil},
+		{"18446744.073709551615", math.MaxUint64, nil},
+		{"18446744.073709551616", 0, ErrorAmountOverflow},
+		{"99999999999999999999", 0, ErrorAmountOverflow},
+		{"1", XMR, nil},
+		{"1.", XMR, nil},
+		{".5", XMR / 2, nil},
+		{"0.0000000000001", 0, ErrorAmountInvalid},
+		{"-1", 0, ErrorAmountInvalid},
+		{"1e3", 0, ErrorAmountInvalid},
+		{"1.2.3", 0, ErrorAmountInvalid},
+		{".", 0, ErrorAmountInvalid},
+		{"", 0, ErrorAmountInvalid},
+	}
+
+	for _, test := range tests {
+		amount, err := ParseXMR(test.s)
+		if amount != test.amount || err != test.err {
+			t.Errorf("ParseXMR(%q) returned %d, %v", test.s, amount, err)
+		}
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		s      string
+	}{
+		{0, "0"},
+		{Piconero, "0.000000000001"},
+		{1234567890123, "1.234567890123"},
+		{50000000000, "0.05"},
+		{3 * XMR, "3"},
+		{math.MaxUint64, "18446744.073709551615"},
+	}
+
+	for _, test := range tests {
+		if s := test.amount.String(); s != test.s {
+			t.Errorf("Amount(%d).String() returned %q", test.amount, s)
+		}
+
+		amount, err := ParseXMR(test.s)
+		if amount != test.amount || err != nil {
+			t.Errorf("ParseXMR(%q) returned %d, %v", test.s, amount, err)
+		}
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	if a, err := XMR.Add(Piconero); a != 1000000000001 || err != nil {
+		t.Error("Add() returned ", a, err)
+	}
+
+	if _, err := Amount(math.MaxUint64).Add(Piconero); err != ErrorAmountOverflow {
+		t.Error("Add() returned the error: ", err)
+	}
+
+	if a, err := XMR.Sub(Piconero); a != 999999999999 || err != nil {
+		t.Error("Sub() returned ", a, err)
+	}
+
+	if _, err := Piconero.Sub(XMR); err != ErrorAmountOverflow {
+		t.Error("Sub() returned the error: ", err)
+	}
+
+	if a, err := XMR.Mul(18446744); a != 18446744*XMR || err != nil {
+		t.Error("Mul() returned ", a, err)
+	}
+
+	if _, err := XMR.Mul(18446745); err != ErrorAmountOverflow {
+		t.Error("Mul() returned the error: ", err)
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	var v struct {
+		Quoted Amount `json:"quoted"`
+		Bare   Amount `json:"bare"`
+		Null   Amount `json:"null"`
+	}
+
+	err := json.Unmarshal([]byte(`{"quoted":"1234567890123","bare":18446744073709551615,"null":null}`), &v)
+	if err != nil {
+		t.Fatal("json.Unmarshal() returned the error: ", err)
+	}
+
+	if v.Quoted != 1234567890123 || v.Bare != math.MaxUint64 || v.Null != 0 {
+		t.Errorf("the amounts were decoded as %+v", v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil || string(b) != `{"quoted":"1234567890123","bare":"18446744073709551615","null":"0"}` {
+		t.Error("json.Marshal() returned ", string(b), err)
+	}
+
+	for _, s := range []string{`{"bare":-1}`, `{"bare":1.5}`, `{"quoted":"1 XMR"}`, `{"bare":18446744073709551616}`} {
+		if json.Unmarshal([]byte(s), &v) == nil {
+			t.Error("json.Unmarshal() accepted ", s)
+		}
+	}
+}
+
+func TestAmountAccessors(t *testing.T) {
+	info := &GetAddressInfoResponse{
+		LockedFunds:   "1000",
+		TotalReceived: "5000000000000",
+		TotalSent:     "1500000000000",
+	}
+
+	balance, err := info.Balance()
+	if balance != 3500000000000 || err != nil {
+		t.Error("Balance() returned ", balance, err)
+	}
+
+	if locked, err := info.LockedFundsAmount(); locked != 1000 || err != nil {
+		t.Error("LockedFundsAmount() returned ", locked, err)
+	}
+
+	info.TotalSent = "6000000000000"
+
+	if _, err := info.Balance(); err != ErrorAmountOverflow {
+		t.Error("Balance() returned the error: ", err)
+	}
+
+	// Servers leave optional amounts out
+	if fee, err := (&ImportRequestResponse{}).ImportFeeAmount(); fee != 0 || err != nil {
+		t.Error("ImportFeeAmount() returned ", fee, err)
+	}
+
+	if _, err := (&Spend{Amount: "lots"}).AmountValue(); err != ErrorAmountInvalid {
+		t.Error("AmountValue() returned the error: ", err)
+	}
+
+	outs := &GetUnspentOutsResponse{PerByteFee: "24658", FeeMask: "10000", Outputs: []Output{{Amount: "314159"}}}
+
+	if fee, err := outs.PerByteFeeAmount(); fee != 24658 || err != nil {
+		t.Error("PerByteFeeAmount() returned ", fee, err)
+	}
+
+	if value, err := outs.Outputs[0].AmountValue(); value != 314159 || err != nil {
+		t.Error("AmountValue() returned ", value, err)
+	}
+
+	if received, err := (&GetAddressTxsResponse{TotalReceived: "31415926535897"}).TotalReceivedAmount(); received != 31415926535897 || err != nil {
+		t.Error("TotalReceivedAmount() returned ", received, err)
+	}
+}
@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// serverTypeLWS is the ServerType monero-lws reports from /get_version
+const serverTypeLWS = "monero-lws"
+
+// probedEndpoints lists the endpoints Capabilities checks for
+var probedEndpoints = []Endpoint{
+	EndpointLogin,
+	EndpointGetAddressInfo,
+	EndpointGetAddressTxs,
+	EndpointGetUnspentOuts,
+	EndpointGetRandomOuts,
+	EndpointImportRequest,
+	EndpointSubmitRawTx,
+	EndpointGetSubaddrs,
+	EndpointUpsertSubaddrs,
+	EndpointProvisionSubaddrs,
+}
+
+// Capabilities describes what a light wallet server supports.
+type Capabilities struct {
+	ServerURL        string                // The server that was asked
+	ServerType       string                // eg. "monero-lws". Empty if the server doesn't support /get_version.
+	ServerVersion    string                // Empty if the server doesn't support /get_version
+	APIMajor         uint16                // The server's API revision. 0 if the server doesn't support /get_version.
+	APIMinor         uint16                //
+	Endpoints        []Endpoint            // The endpoints the server supports
+	Subaddresses     bool                  // Whether the server supports subaddresses (eg. /get_subaddrs)
+	MaxSubaddresses  uint32                // The most subaddresses an account can have, if the server reported it
+	Binary           bool                  // Whether the server answered a binary (epee) request in kind, see EncodingBinary
+	Network          string                // eg. "main". Empty if the server didn't report it.
+	BlockchainHeight uint64                // The server's blockchain height, if it reported it
+	Daemon           *DaemonStatusResponse // The status of the server's daemon. Nil if the server doesn't support /daemon_status.
+}
+
+// Supports reports whether the server supports endpoint 'e'.
+func (c *Capabilities) Supports(e Endpoint) bool {
+	for _, endpoint := range c.Endpoints {
+		if endpoint == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Capabilities asks one of our servers what it supports.
+//
+// Servers supporting /get_version (eg. monero-lws) and /daemon_status
+// are asked directly. Other servers have each endpoint probed with
+// an empty request, which is assumed to be supported unless the
+// server responds with HTTP 404 or 501 (see ErrorUnsupported).
+//
+// Endpoints with side effects (eg. /submit_raw_tx) aren't probed. The
+// subaddress ones are assumed to be supported if /get_subaddrs is, and
+// the others (eg. /login) are assumed to be, like every light wallet
+// server should.
+//
+// Probes are sent once, and aren't seen by our client's interceptors
+// (eg. metrics), so they don't look like our caller's own calls.
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	server := c.pickServer("")
+
+	caps := &Capabilities{ServerURL: server}
+
+	version := &GetVersionResponse{}
+
+	err := c.do(ctx, &Call{
+		Endpoint:  EndpointGetVersion,
+		Request:   &struct{}{},
+		Response:  version,
+		encodeErr: ErrorStandardRequestEncode,
+		server:    server,
+	})
+	if err != nil && !errors.Is(err, ErrorUnsupported) {
+		return &Capabilities{}, err
+	}
+
+	if err == nil {
+		caps.ServerType = version.ServerType
+		caps.ServerVersion = version.ServerVersion
+		caps.APIMajor = uint16(version.API >> 16)
+		caps.APIMinor = uint16(version.API)
+		caps.MaxSubaddresses = version.MaxSubaddresses
+		caps.Network = version.Network
+		caps.BlockchainHeight = version.BlockchainHeight
+	}
+
+	daemon := &DaemonStatusResponse{}
+
+	err = c.do(ctx, &Call{
+		Endpoint:  EndpointDaemonStatus,
+		Request:   &struct{}{},
+		Response:  daemon,
+		encodeErr: ErrorStandardRequestEncode,
+		server:    server,
+	})
+	if err != nil && !errors.Is(err, ErrorUnsupported) {
+		return &Capabilities{}, err
+	}
+
+	if err == nil {
+		caps.Daemon = daemon
+
+		if caps.Network == "" {
+			caps.Network = daemon.Network
+		}
+	}
+
+	if caps.ServerType == serverTypeLWS {
+		// monero-lws supports every endpoint, but only
+		// supports subaddresses if they're enabled.
+		for _, endpoint := range probedEndpoints {
+			if isSubaddrEndpoint(endpoint) && caps.MaxSubaddresses == 0 {
+				continue
+			}
+
+			caps.Endpoints = append(caps.Endpoints, endpoint)
+		}
+	} else {
+		supported := map[Endpoint]bool{}
+
+		for _, endpoint := range probedEndpoints {
+			if !probeable(endpoint) {
+				continue
+			}
+
+			ok, err := c.probe(ctx, server, endpoint)
+			if err != nil {
+				return &Capabilities{}, err
+			}
+
+			supported[endpoint] = ok
+		}
+
+		for _, endpoint := range probedEndpoints {
+			ok, probed := supported[endpoint]
+
+			switch {
+			case probed:
+			case isSubaddrEndpoint(endpoint):
+				ok = supported[EndpointGetSubaddrs]
+			default:
+				ok = true
+			}
+
+			if ok {
+				caps.Endpoints = append(caps.Endpoints, endpoint)
+			}
+		}
+	}
+
+	caps.Subaddresses = caps.Supports(EndpointGetSubaddrs)
+
+	caps.Binary, err = c.probeBinary(ctx, server)
+	if err != nil {
+		return &Capabilities{}, err
+	}
+
+	return caps, nil
+}
+
+// probe reports whether 'server' supports 'endpoint' by posting an empty
+// request to it. Only transport errors (eg. ctx being done) are returned.
+func (c *Client) probe(ctx context.Context, server string, endpoint Endpoint) (bool, error) {
+	err := c.sendProbe(ctx, &Call{
+		Endpoint:  endpoint,
+		Request:   &struct{}{},
+		Response:  &json.RawMessage{},
+		encodeErr: ErrorStandardRequestEncode,
+		server:    server,
+	})
+
+	var apiErr *APIError
+
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrorUnsupported):
+		return false, nil
+	case errors.As(err, &apiErr) && apiErr.StatusCode != 0:
+		return true, nil // The endpoint exists, but didn't like our empty request
+	case errors.Is(err, ErrorResponseUnmarshalFailed):
+		return true, nil
+	}
+
+	return false, err
+}
+
+// probeBinary reports whether 'server' answers a binary /get_address_info request in kind.
+func (c *Client) probeBinary(ctx context.Context, server string) (bool, error) {
+	cl := &Call{
+		Endpoint:  EndpointGetAddressInfo,
+		Request:   &StandardRequest{Address: c.address, ViewKey: c.viewKey},
+		encodeErr: ErrorStandardRequestEncode,
+		server:    server,
+		binary:    true,
+	}
+
+	err := c.sendProbe(ctx, cl)
+
+	var apiErr *APIError
+
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrorStandardRequestEncode):
+		return false, nil // Our keys can't be sent in binary, so neither can our requests
+	case errors.Is(err, ErrorUnsupported):
+		return false, nil
+	case errors.As(err, &apiErr) && apiErr.StatusCode != 0:
+	default:
+		return false, err
+	}
+
+	return cl.StatusCode != http.StatusUnsupportedMediaType && cl.mediaType == contentTypeEpee, nil
+}
+
+// sendProbe makes probe 'cl' once, without our client's interceptors
+// or RetryPolicy, so probes aren't seen (eg. by metrics) as our
+// caller's calls.
+func (c *Client) sendProbe(ctx context.Context, cl *Call) error {
+	cl.Address = c.address
+	cl.Header = http.Header{}
+	cl.noRetry = true
+
+	return c.send(ctx, cl)
+}
+
+// probeable reports whether 'e' can be probed without side
+// effects (eg. creating an account or requesting a rescan)
+func probeable(e Endpoint) bool {
+	switch e {
+	case EndpointLogin, EndpointImportRequest, EndpointUpsertSubaddrs:
+		return false
+	}
+
+	return e.Idempotent()
+}
+
+// isSubaddrEndpoint reports whether 'e' is one of the subaddress endpoints
+func isSubaddrEndpoint(e Endpoint) bool {
+	return e == EndpointGetSubaddrs || e == EndpointUpsertSubaddrs || e == EndpointProvisionSubaddrs
+}
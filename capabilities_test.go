@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCapabilitiesLWS(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch Endpoint(r.URL.Path) {
+		case EndpointGetVersion:
+			err := json.NewEncoder(w).Encode(GetVersionResponse{
+				ServerType:       "monero-lws",
+				ServerVersion:    "0.3_0",
+				API:              1<<16 | 2,
+				MaxSubaddresses:  0,
+				Network:          "stage",
+				BlockchainHeight: 1500000,
+			})
+			if err != nil {
+				t.Error("failed to marshal our response")
+			}
+		case EndpointDaemonStatus:
+			_, err := w.Write([]byte(`{"height":1500000,"target_height":1500000,"network":"stage","state":"ok"}`))
+			if err != nil {
+				t.Error("failed to write our response")
+			}
+		case EndpointGetAddressInfo:
+			if r.Header.Get("Content-Type") == contentTypeEpee {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+
+				return
+			}
+
+			t.Error("Capabilities() sent a JSON request to ", r.URL.Path)
+		default:
+			t.Error("Capabilities() probed ", r.URL.Path)
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	caps, err := client.Capabilities(context.Background())
+	if err != nil {
+		t.Fatal("Capabilities() returned the error: ", err)
+	}
+
+	if caps.ServerType != "monero-lws" || caps.APIMajor != 1 || caps.APIMinor != 2 || caps.Network != "stage" {
+		t.Errorf("Capabilities() returned the wrong server info: %+v", caps)
+	}
+
+	// Subaddresses are disabled on the server
+	if caps.Subaddresses || caps.Supports(EndpointGetSubaddrs) || !caps.Supports(EndpointLogin) {
+		t.Error("Capabilities() returned the endpoints: ", caps.Endpoints)
+	}
+
+	if caps.Binary {
+		t.Error("Capabilities() reported binary support")
+	}
+
+	if caps.Daemon == nil || !caps.Daemon.Synced() {
+		t.Error("Capabilities() returned the daemon status: ", caps.Daemon)
+	}
+}
+
+func TestCapabilitiesProbe(t *testing.T) {
+	randomOutsProbes := 0
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch Endpoint(r.URL.Path) {
+		case EndpointLogin, EndpointImportRequest, EndpointSubmitRawTx, EndpointUpsertSubaddrs, EndpointProvisionSubaddrs:
+			t.Error("the client probed an endpoint with side effects: ", r.URL.Path)
+		case EndpointGetAddressInfo:
+			if r.Header.Get("Content-Type") == contentTypeEpee {
+				w.Header().Set("Content-Type", contentTypeEpee)
+
+				b, err := MarshalEpee(&GetAddressInfoResponse{})
+				if err != nil {
+					t.Error("failed to marshal our response")
+				}
+
+				_, _ = w.Write(b)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{}`))
+		case EndpointGetAddressTxs, EndpointGetUnspentOuts:
+			_, _ = w.Write([]byte(`{}`))
+		case EndpointGetRandomOuts:
+			randomOutsProbes++
+
+			w.WriteHeader(http.StatusBadGateway) // The endpoint exists, so our probe isn't retried
+		case EndpointGetSubaddrs:
+			w.WriteHeader(http.StatusNotImplemented)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	var intercepted []Endpoint
+
+	client := &Client{
+		address: "xmr_address",
+		client:  &http.Client{},
+		interceptors: []Interceptor{func(ctx context.Context, call *Call, next Invoker) error {
+			intercepted = append(intercepted, call.Endpoint)
+
+			return next(ctx, call)
+		}},
+		retryCount: 3,
+		serverURL:  ts.URL,
+		viewKey:    "7b0a6b7e5a1f3a4dbe5e0f1a9c8e7d6c5b4a39281706f5e4d3c2b1a098f7e60d",
+	}
+
+	caps, err := client.Capabilities(context.Background())
+	if err != nil {
+		t.Fatal("Capabilities() returned the error: ", err)
+	}
+
+	expected := []Endpoint{
+		EndpointLogin,
+		EndpointGetAddressInfo,
+		EndpointGetAddressTxs,
+		EndpointGetUnspentOuts,
+		EndpointGetRandomOuts,
+		EndpointImportRequest,
+		EndpointSubmitRawTx,
+	}
+
+	if !reflect.DeepEqual(caps.Endpoints, expected) {
+		t.Error("Capabilities() returned the endpoints: ", caps.Endpoints)
+	}
+
+	if caps.ServerType != "" || caps.Daemon != nil || caps.Subaddresses {
+		t.Errorf("Capabilities() returned: %+v", caps)
+	}
+
+	if !caps.Binary {
+		t.Error("Capabilities() didn't report binary support")
+	}
+
+	// Probes aren't our caller's calls, so they aren't intercepted or retried
+	if !reflect.DeepEqual(intercepted, []Endpoint{EndpointGetVersion, EndpointDaemonStatus}) {
+		t.Error("our interceptor saw the calls: ", intercepted)
+	}
+
+	if randomOutsProbes != 1 {
+		t.Errorf("/get_random_outs was probed %d times", randomOutsProbes)
+	}
+
+	if caps.ServerURL != ts.URL {
+		t.Error("Capabilities() asked the server ", caps.ServerURL)
+	}
+}
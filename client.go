@@ -4,17 +4,32 @@
 package gomonerolight
 
 import (
+	"log/slog"
 	"net/http"
+	"sync/atomic"
 	"time"
 )
 
 type Client struct {
-	address    string
-	client     *http.Client
-	retryCount int
-	retryTime  time.Duration
-	serverURL  string
-	viewKey    string
+	accountState     atomic.Int32 // Our AccountState
+	address          string
+	consensusServers int
+	client           *http.Client
+	encoding         Encoding
+	binaryRejected   atomic.Bool // Set once a server rejects EncodingBinary, so we stick to JSON
+	interceptors     []Interceptor
+	lastLogin        atomic.Pointer[LoginRequest] // The request from our last call to Login, for WaitForActivation
+	serverClients    map[string]*http.Client
+	logger           *slog.Logger
+	maxResponseSizes map[Endpoint]int64
+	retryCount       int
+	retryTime        time.Duration
+	retryPolicy      RetryPolicy
+	serverURL        string
+	pool             *serverPool
+	rateLimiter      *RateLimiter
+	strictDecoding   bool
+	viewKey          string
 }
 
 // NewClient creates a new client using the
@@ -22,18 +37,38 @@ type Client struct {
 // and getting a client 'c', call c.Login()
 // and then the subsequent methods you need.
 func NewClient(cfg Config) (*Client, error) {
+	return newClient(cfg, true)
+}
+
+// newClient creates a new client using 'cfg', which
+// only needs an address and view key if 'account' is set.
+func newClient(cfg Config, account bool) (*Client, error) {
 	c := &Client{}
 
-	err := checkConfig(&cfg)
+	err := checkConfig(&cfg, account)
+	if err != nil {
+		return nil, err
+	}
+
+	c.serverClients, err = newServerHTTPClients(&cfg, cfg.HTTPClient)
 	if err != nil {
 		return nil, err
 	}
 
 	c.address = cfg.Address
+	c.consensusServers = cfg.ConsensusServers
 	c.client = cfg.HTTPClient
+	c.encoding = cfg.Encoding
+	c.interceptors = cfg.Interceptors
+	c.logger = cfg.Logger
+	c.maxResponseSizes = cfg.MaxResponseSizes
 	c.retryCount = cfg.RetryCount
 	c.retryTime = cfg.RetryTime
-	c.serverURL = cfg.ServerURL
+	c.retryPolicy = cfg.RetryPolicy
+	c.rateLimiter = cfg.RateLimiter
+	c.pool = newServerPool(cfg.serverURLs())
+	c.serverURL = c.pool.servers[0].URL
+	c.strictDecoding = cfg.StrictDecoding
 	c.viewKey = cfg.ViewKey
 
 	return c, nil
@@ -5,45 +5,93 @@ package gomonerolight
 
 import (
 	"errors"
-	"fmt"
+	"log/slog"
 	"net/http"
-	"os"
 	"time"
 )
 
 var ErrorBadConfig = errors.New("configuration options passed to NewClient were invalid")
 
 type Config struct {
-	Address    string        // Your XMR address
-	HTTPClient *http.Client  // For setting custom cookies, etc. Likely to remain unused.
-	RetryCount int           // The number of times to retry a method call before giving up
-	RetryTime  time.Duration // The time to wait in between retry requests
-	ServerURL  string        // The URL of the API server (eg. https://api.mymonero.com)
-	ViewKey    string        // Your XMR private view key
+	Address          string               // Your XMR address
+	ConsensusServers int                  // The number of servers to ask in consensus requests (eg. GetAddressTxsConsensus). Defaults to all of them, otherwise it must be at least 2.
+	Encoding         Encoding             // The wire format requests are sent in. Defaults to EncodingJSON.
+	HTTPClient       *http.Client         // For setting custom cookies, etc. Likely to remain unused.
+	Interceptors     []Interceptor        // Run around every call (eg. to add headers or measure latency), the first one outermost
+	Logger           *slog.Logger         // Where to log requests, retries and failures. Secrets are redacted. Defaults to logging nothing.
+	MaxResponseSizes map[Endpoint]int64   // The largest response to accept from each endpoint, in bytes. Defaults to 1 MiB, or up to 64 MiB for endpoints like /get_address_txs.
+	Proxy            string               // A SOCKS5 proxy to send requests through (eg. socks5://127.0.0.1:9050 for Tor). Required for .onion servers.
+	RateLimiter      *RateLimiter         // Limits how fast requests are sent. Share one between clients using the same server.
+	RetryCount       int                  // The number of times to retry a method call before giving up
+	RetryTime        time.Duration        // The time to wait before the first retry. It's doubled for each subsequent retry.
+	RetryPolicy      RetryPolicy          // Decides which failed calls are retried. Defaults to a BackoffRetryPolicy using RetryCount and RetryTime.
+	ServerURL        string               // The URL of the API server (eg. https://api.mymonero.com)
+	ServerURLs       []string             // URLs of more API servers to fail over to. Requests are sent to the healthiest server, and idempotent calls failing with a transport error or HTTP 5xx are sent to each other healthy server once, even if RetryCount is 0.
+	ServerTLS        map[string]ServerTLS // Certificate pins and CAs to trust for self-hosted servers, keyed by server URL
+	StrictDecoding   bool                 // Reject responses with fields we don't know, or values that had to be converted to fit their field
+	ViewKey          string               // Your XMR private view key
 }
 
-func checkConfig(cfg *Config) error {
-	if cfg.Address == "" {
+// checkConfig fills in 'cfg's defaults and checks it's valid. The
+// address and view key are only required if 'account' is set.
+func checkConfig(cfg *Config, account bool) error {
+	cfg.Logger = newLogger(cfg.Logger)
+
+	if account && cfg.Address == "" {
 		// TODO: Generate a new, random address (and viewkey) if one is not provided
 
-		_, _ = fmt.Fprintf(os.Stderr, "no XMR address was passed to NewClient() call\n")
+		cfg.Logger.Error("no XMR address was passed to NewClient() call")
 
 		return ErrorBadConfig
 	}
 
-	if cfg.HTTPClient == nil {
-		cfg.HTTPClient = &http.Client{}
+	// Consensus needs at least two servers to compare
+	if cfg.ConsensusServers < 0 || cfg.ConsensusServers == 1 {
+		cfg.Logger.Error("ConsensusServers must be 0 (all servers) or at least 2", slog.Int("consensus_servers", cfg.ConsensusServers))
+
+		return ErrorBadConfig
 	}
 
-	if cfg.ServerURL == "" {
+	if cfg.RetryPolicy == nil {
+		cfg.RetryPolicy = &BackoffRetryPolicy{
+			MaxRetries: cfg.RetryCount,
+			BaseDelay:  cfg.RetryTime,
+		}
+	}
+
+	// ServerURLs holding only empty strings are as good as none
+	if len(cfg.serverURLs()) == 0 {
 		cfg.ServerURL = "https://api.mymonero.com" //Default to using MyMonero
 	}
 
-	if cfg.ViewKey == "" {
-		_, _ = fmt.Fprintf(os.Stderr, "no viewkey was passed to NewClient call\n")
+	if account && cfg.ViewKey == "" {
+		cfg.Logger.Error("no viewkey was passed to NewClient call")
 
 		return ErrorBadConfig
 	}
 
+	client, err := newHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	cfg.HTTPClient = client
+
 	return nil
 }
+
+// serverURLs returns every server URL in 'cfg', without duplicates.
+func (cfg *Config) serverURLs() []string {
+	var urls []string
+
+	seen := map[string]bool{}
+
+	for _, url := range append([]string{cfg.ServerURL}, cfg.ServerURLs...) {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+
+	return urls
+}
@@ -0,0 +1,383 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrorConsensusTooFewServers = errors.New("fewer than two servers responded to a consensus request")
+
+// ConsensusReport describes how the servers asked
+// in a consensus request agreed with each other.
+type ConsensusReport struct {
+	Servers       []string         // The servers that responded
+	Failed        map[string]error // The servers that didn't respond, and why
+	Discrepancies []Discrepancy    // The fields the servers disagreed on
+}
+
+// Agreed reports whether every server that responded agreed on everything.
+func (r *ConsensusReport) Agreed() bool {
+	return len(r.Discrepancies) == 0
+}
+
+// Discrepancy is a single field that servers disagreed on.
+//
+// For a set of transactions (or spends), Field is "Transactions"
+// (or "SpentOutputs"), Hash is the transaction hash (or key image)
+// that some servers left out, and Values is either "present" or "missing".
+// Fields of a transaction (or spend) the servers disagreed on are named
+// like "Transactions.Height", with Hash set to the transaction's hash.
+type Discrepancy struct {
+	Field  string            // The name of the field (eg. "TotalReceived")
+	Hash   string            // The transaction hash or key image, for sets
+	Values map[string]string // The value each server reported, by server URL
+	Chosen string            // The value used in our merged response
+}
+
+// GetAddressInfoConsensus calls /get_address_info on several of
+// our servers (see Config.ConsensusServers) and compares the results.
+//
+// The merged response uses the value most servers agreed on for
+// each field, preferring the first healthy server in our config on
+// ties, along with the spends most servers reported.
+func (c *Client) GetAddressInfoConsensus(ctx context.Context) (*GetAddressInfoResponse, *ConsensusReport, error) {
+	servers, responses, report, err := c.consensus(ctx, EndpointGetAddressInfo, func() interface{} { return &GetAddressInfoResponse{} })
+	if err != nil {
+		return &GetAddressInfoResponse{}, report, err
+	}
+
+	infos := make([]*GetAddressInfoResponse, len(responses))
+	for i, r := range responses {
+		infos[i] = r.(*GetAddressInfoResponse)
+	}
+
+	merged := *infos[0]
+
+	fields := []struct {
+		name  string
+		value func(*GetAddressInfoResponse) string
+		set   func(string)
+	}{
+		{"LockedFunds", func(r *GetAddressInfoResponse) string { return r.LockedFunds }, func(v string) { merged.LockedFunds = v }},
+		{"TotalReceived", func(r *GetAddressInfoResponse) string { return r.TotalReceived }, func(v string) { merged.TotalReceived = v }},
+		{"TotalSent", func(r *GetAddressInfoResponse) string { return r.TotalSent }, func(v string) { merged.TotalSent = v }},
+		{"ScannedHeight", func(r *GetAddressInfoResponse) string { return strconv.FormatUint(r.ScannedHeight, 10) }, func(v string) { merged.ScannedHeight = parseHeight(v) }},
+		{"ScannedBlockHeight", func(r *GetAddressInfoResponse) string { return strconv.FormatUint(r.ScannedBlockHeight, 10) }, func(v string) { merged.ScannedBlockHeight = parseHeight(v) }},
+		{"StartHeight", func(r *GetAddressInfoResponse) string { return strconv.FormatUint(r.StartHeight, 10) }, func(v string) { merged.StartHeight = parseHeight(v) }},
+		{"TransactionHeight", func(r *GetAddressInfoResponse) string { return strconv.FormatUint(r.TransactionHeight, 10) }, func(v string) { merged.TransactionHeight = parseHeight(v) }},
+		{"BlockchainHeight", func(r *GetAddressInfoResponse) string { return strconv.FormatUint(r.BlockchainHeight, 10) }, func(v string) { merged.BlockchainHeight = parseHeight(v) }},
+	}
+
+	for _, f := range fields {
+		values := make([]string, len(infos))
+		for i, info := range infos {
+			values[i] = f.value(info)
+		}
+
+		f.set(report.compare(f.name, servers, values))
+	}
+
+	spends := make([][]Spend, len(infos))
+	for i, info := range infos {
+		spends[i] = info.SpentOutputs
+	}
+
+	merged.SpentOutputs = mergeSet(report, "SpentOutputs", servers, spends, spendKey, spendFields)
+
+	return &merged, report, nil
+}
+
+// GetAddressTxsConsensus calls /get_address_txs on several of
+// our servers (see Config.ConsensusServers) and compares the results.
+//
+// The merged response uses the value most servers agreed on for
+// each field, preferring the first healthy server in our config on
+// ties, along with the transactions most servers reported.
+func (c *Client) GetAddressTxsConsensus(ctx context.Context) (*GetAddressTxsResponse, *ConsensusReport, error) {
+	servers, responses, report, err := c.consensus(ctx, EndpointGetAddressTxs, func() interface{} { return &GetAddressTxsResponse{} })
+	if err != nil {
+		return &GetAddressTxsResponse{}, report, err
+	}
+
+	txs := make([]*GetAddressTxsResponse, len(responses))
+	for i, r := range responses {
+		txs[i] = r.(*GetAddressTxsResponse)
+	}
+
+	merged := *txs[0]
+
+	fields := []struct {
+		name  string
+		value func(*GetAddressTxsResponse) string
+		set   func(string)
+	}{
+		{"TotalReceived", func(r *GetAddressTxsResponse) string { return r.TotalReceived }, func(v string) { merged.TotalReceived = v }},
+		{"ScannedHeight", func(r *GetAddressTxsResponse) string { return strconv.FormatUint(r.ScannedHeight, 10) }, func(v string) { merged.ScannedHeight = parseHeight(v) }},
+		{"ScannedBlockHeight", func(r *GetAddressTxsResponse) string { return strconv.FormatUint(r.ScannedBlockHeight, 10) }, func(v string) { merged.ScannedBlockHeight = parseHeight(v) }},
+		{"StartHeight", func(r *GetAddressTxsResponse) string { return strconv.FormatUint(r.StartHeight, 10) }, func(v string) { merged.StartHeight = parseHeight(v) }},
+		{"BlockchainHeight", func(r *GetAddressTxsResponse) string { return strconv.FormatUint(r.BlockchainHeight, 10) }, func(v string) { merged.BlockchainHeight = parseHeight(v) }},
+	}
+
+	for _, f := range fields {
+		values := make([]string, len(txs))
+		for i, tx := range txs {
+			values[i] = f.value(tx)
+		}
+
+		f.set(report.compare(f.name, servers, values))
+	}
+
+	sets := make([][]Transaction, len(txs))
+	for i, r := range txs {
+		sets[i] = r.Transactions
+	}
+
+	merged.Transactions = mergeSet(report, "Transactions", servers, sets, func(tx Transaction) string { return tx.Hash }, transactionFields)
+
+	return &merged, report, nil
+}
+
+// consensus sends a StandardRequest to 'endpoint' on several of our
+// servers at once, returning the servers that responded and their
+// responses. Healthy servers come first, in the order they appear
+// in our config.
+func (c *Client) consensus(ctx context.Context, endpoint Endpoint, newResponse func() interface{}) ([]string, []interface{}, *ConsensusReport, error) {
+	report := &ConsensusReport{Failed: map[string]error{}}
+
+	all := c.Servers()
+
+	// Prefer healthy servers, but keep them in the order they're configured in
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].State == ServerHealthy && all[j].State != ServerHealthy
+	})
+
+	if c.consensusServers > 0 && c.consensusServers < len(all) {
+		all = all[:c.consensusServers]
+	}
+
+	responses := make([]interface{}, len(all))
+	errs := make([]error, len(all))
+
+	var wg sync.WaitGroup
+
+	for i, s := range all {
+		wg.Add(1)
+
+		go func(i int, server string) {
+			defer wg.Done()
+
+			responses[i] = newResponse()
+
+			errs[i] = c.do(ctx, &Call{
+				Endpoint: endpoint,
+				Request: &StandardRequest{
+					Address: c.address,
+					ViewKey: c.viewKey,
+				},
+				Response:  responses[i],
+				encodeErr: ErrorStandardRequestEncode,
+				server:    server,
+			})
+		}(i, s.URL)
+	}
+
+	wg.Wait()
+
+	var servers []string
+	var ok []interface{}
+
+	for i, s := range all {
+		if errs[i] != nil {
+			report.Failed[s.URL] = errs[i]
+
+			continue
+		}
+
+		servers = append(servers, s.URL)
+		ok = append(ok, responses[i])
+	}
+
+	report.Servers = servers
+
+	if len(servers) < 2 {
+		if len(servers) == 0 && len(all) > 0 {
+			return nil, nil, report, errs[0]
+		}
+
+		return nil, nil, report, ErrorConsensusTooFewServers
+	}
+
+	return servers, ok, report, nil
+}
+
+// compare records a discrepancy if 'values' (reported by 'servers')
+// aren't all equal, and returns the value most servers agreed on.
+func (r *ConsensusReport) compare(field string, servers []string, values []string) string {
+	return r.compareHash(field, "", servers, values)
+}
+
+// compareHash is like compare, for a field of the set element 'hash'.
+func (r *ConsensusReport) compareHash(field string, hash string, servers []string, values []string) string {
+	counts := map[string]int{}
+	chosen := values[0]
+
+	for _, v := range values {
+		counts[v]++
+
+		if counts[v] > counts[chosen] {
+			chosen = v
+		}
+	}
+
+	if len(counts) > 1 {
+		d := Discrepancy{Field: field, Hash: hash, Values: map[string]string{}, Chosen: chosen}
+		for i, s := range servers {
+			d.Values[s] = values[i]
+		}
+
+		r.Discrepancies = append(r.Discrepancies, d)
+	}
+
+	return chosen
+}
+
+// setField is a field of a set's elements (eg. a transaction's
+// Height) that servers reporting the same element should agree on.
+type setField[T any] struct {
+	name  string
+	value func(T) string
+	copy  func(dst *T, src T) // Sets the field in 'dst' to its value in 'src'
+}
+
+var transactionFields = []setField[Transaction]{
+	{"Timestamp", func(tx Transaction) string { return tx.Timestamp.UTC().String() }, func(dst *Transaction, src Transaction) { dst.Timestamp = src.Timestamp }},
+	{"TotalReceived", func(tx Transaction) string { return tx.TotalReceived }, func(dst *Transaction, src Transaction) { dst.TotalReceived = src.TotalReceived }},
+	{"TotalSent", func(tx Transaction) string { return tx.TotalSent }, func(dst *Transaction, src Transaction) { dst.TotalSent = src.TotalSent }},
+	{"UnlockTime", func(tx Transaction) string { return strconv.FormatUint(tx.UnlockTime, 10) }, func(dst *Transaction, src Transaction) { dst.UnlockTime = src.UnlockTime }},
+	{"Height", func(tx Transaction) string { return strconv.FormatUint(tx.Height, 10) }, func(dst *Transaction, src Transaction) { dst.Height = src.Height }},
+	{"SpentOutputs", spentKeyImages, func(dst *Transaction, src Transaction) { dst.SpentOutputs = src.SpentOutputs }},
+	{"PaymentID", func(tx Transaction) string { return tx.PaymentID }, func(dst *Transaction, src Transaction) { dst.PaymentID = src.PaymentID }},
+	{"Coinbase", func(tx Transaction) string { return strconv.FormatBool(tx.Coinbase) }, func(dst *Transaction, src Transaction) { dst.Coinbase = src.Coinbase }},
+	{"Mempool", func(tx Transaction) string { return strconv.FormatBool(tx.Mempool) }, func(dst *Transaction, src Transaction) { dst.Mempool = src.Mempool }},
+	{"Mixin", func(tx Transaction) string { return strconv.FormatUint(tx.Mixin, 10) }, func(dst *Transaction, src Transaction) { dst.Mixin = src.Mixin }},
+}
+
+var spendFields = []setField[Spend]{
+	{"Amount", func(s Spend) string { return s.Amount }, func(dst *Spend, src Spend) { dst.Amount = src.Amount }},
+	{"TxPublicKey", func(s Spend) string { return s.TxPublicKey }, func(dst *Spend, src Spend) { dst.TxPublicKey = src.TxPublicKey }},
+	{"OutIndex", func(s Spend) string { return strconv.FormatUint(uint64(s.OutIndex), 10) }, func(dst *Spend, src Spend) { dst.OutIndex = src.OutIndex }},
+	{"Mixin", func(s Spend) string { return strconv.FormatUint(uint64(s.Mixin), 10) }, func(dst *Spend, src Spend) { dst.Mixin = src.Mixin }},
+}
+
+func spendKey(s Spend) string {
+	return s.KeyImage
+}
+
+// spentKeyImages returns the key images of the spends in 'tx', sorted and joined
+func spentKeyImages(tx Transaction) string {
+	keyImages := make([]string, len(tx.SpentOutputs))
+	for i, s := range tx.SpentOutputs {
+		keyImages[i] = s.KeyImage
+	}
+
+	sort.Strings(keyImages)
+
+	return strings.Join(keyImages, ",")
+}
+
+// mergeSet records a discrepancy for each element that isn't in every one of
+// 'sets' (reported by 'servers', and identified by 'key'), and returns the
+// elements most servers reported in the order they were first seen. Each of
+// their 'fields' is set to the value most servers reporting them agreed on.
+func mergeSet[T any](r *ConsensusReport, field string, servers []string, sets [][]T, key func(T) string, fields []setField[T]) []T {
+	var order []string
+
+	byKey := make([]map[string]T, len(sets))
+	seen := map[string]bool{}
+
+	for i, set := range sets {
+		byKey[i] = map[string]T{}
+
+		for _, e := range set {
+			k := key(e)
+			byKey[i][k] = e
+
+			if !seen[k] {
+				seen[k] = true
+				order = append(order, k)
+			}
+		}
+	}
+
+	var merged []T
+
+	for _, k := range order {
+		var reporters []string
+		var elements []T
+
+		d := Discrepancy{Field: field, Hash: k, Values: map[string]string{}, Chosen: "missing"}
+
+		for i, s := range servers {
+			e, ok := byKey[i][k]
+			if !ok {
+				d.Values[s] = "missing"
+
+				continue
+			}
+
+			d.Values[s] = "present"
+			reporters = append(reporters, s)
+			elements = append(elements, e)
+		}
+
+		// A single server could make elements up, so most servers have to report them
+		quorum := len(elements)*2 > len(servers)
+		if quorum {
+			d.Chosen = "present"
+		}
+
+		if len(elements) != len(servers) {
+			r.Discrepancies = append(r.Discrepancies, d)
+		}
+
+		if !quorum {
+			continue
+		}
+
+		e := elements[0]
+
+		for _, f := range fields {
+			values := make([]string, len(elements))
+			for i, element := range elements {
+				values[i] = f.value(element)
+			}
+
+			chosen := r.compareHash(field+"."+f.name, k, reporters, values)
+
+			for i, v := range values {
+				if v == chosen {
+					f.copy(&e, elements[i])
+
+					break
+				}
+			}
+		}
+
+		merged = append(merged, e)
+	}
+
+	return merged
+}
+
+func parseHeight(s string) uint64 {
+	height, _ := strconv.ParseUint(s, 10, 64)
+
+	return height
+}
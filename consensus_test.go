@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetAddressTxsConsensus(t *testing.T) {
+	honest := GetAddressTxsResponse{
+		TotalReceived:    "31415926535897",
+		ScannedHeight:    3222370,
+		BlockchainHeight: 3222370,
+		Transactions: []Transaction{
+			{Hash: "a70d679d2052f752732659680f27afe54b83686866c906cb5b4d9c91ce65a942", TotalReceived: "31415926535000"},
+			{Hash: "1adfdf87df1301136ab065e80b24217bcc2feea824a63c4eba31d46f60213fc1", TotalReceived: "897"},
+		},
+	}
+
+	// A server that omits a transaction, makes one up, and lies about our
+	// total received and the height of the transaction it kept
+	liar := honest
+	liar.TotalReceived = "31415926535000"
+	liar.Transactions = []Transaction{honest.Transactions[0], {Hash: "f00d", TotalReceived: "1000000000000"}}
+	liar.Transactions[0].Height = 3222000
+
+	var servers []string
+
+	for _, response := range []GetAddressTxsResponse{honest, liar, honest} {
+		response := response
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := json.NewEncoder(w).Encode(response)
+			if err != nil {
+				t.Error("failed to marshal our response")
+			}
+		}))
+		defer ts.Close()
+
+		servers = append(servers, ts.URL)
+	}
+
+	client, err := NewClient(Config{
+		Address:    "xmr_address",
+		ServerURLs: servers,
+		ViewKey:    "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	resp, report, err := client.GetAddressTxsConsensus(context.Background())
+	if err != nil {
+		t.Fatal("GetAddressTxsConsensus() returned the error: ", err)
+	}
+
+	if resp.TotalReceived != honest.TotalReceived {
+		t.Error("the merged TotalReceived was ", resp.TotalReceived)
+	}
+
+	if !reflect.DeepEqual(resp.Transactions, honest.Transactions) {
+		t.Error("the merged transactions were ", resp.Transactions)
+	}
+
+	if report.Agreed() || len(report.Servers) != 3 {
+		t.Fatalf("the report was %+v", report)
+	}
+
+	fields := map[string]Discrepancy{}
+	for _, d := range report.Discrepancies {
+		fields[d.Field+d.Hash] = d
+	}
+
+	if d := fields["TotalReceived"]; d.Values[servers[1]] != liar.TotalReceived || d.Chosen != honest.TotalReceived {
+		t.Errorf("the TotalReceived discrepancy was %+v", d)
+	}
+
+	if d := fields["Transactions"+honest.Transactions[1].Hash]; d.Values[servers[1]] != "missing" || d.Chosen != "present" {
+		t.Errorf("the missing transaction's discrepancy was %+v", d)
+	}
+
+	if d := fields["Transactionsf00d"]; d.Values[servers[1]] != "present" || d.Values[servers[0]] != "missing" || d.Chosen != "missing" {
+		t.Errorf("the made up transaction's discrepancy was %+v", d)
+	}
+
+	if d := fields["Transactions.Height"+honest.Transactions[0].Hash]; d.Values[servers[1]] != "3222000" || d.Chosen != "0" {
+		t.Errorf("the Transactions.Height discrepancy was %+v", d)
+	}
+
+	if len(report.Discrepancies) != 4 {
+		t.Errorf("the report had the discrepancies %+v", report.Discrepancies)
+	}
+
+	if _, ok := fields["ScannedHeight"]; ok {
+		t.Error("the servers agreed on ScannedHeight, but a discrepancy was reported")
+	}
+}
+
+func TestGetAddressInfoConsensusTooFewServers(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(GetAddressInfoResponse{})
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}))
+	defer up.Close()
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer down.Close()
+
+	client, err := NewClient(Config{
+		Address:    "xmr_address",
+		ServerURLs: []string{up.URL, down.URL},
+		ViewKey:    "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	_, report, err := client.GetAddressInfoConsensus(context.Background())
+	if !errors.Is(err, ErrorConsensusTooFewServers) {
+		t.Error("GetAddressInfoConsensus() returned the error: ", err)
+	}
+
+	if !errors.Is(report.Failed[down.URL], ErrorStatusCodeNotOK) {
+		t.Errorf("the report was %+v", report)
+	}
+}
+
+func TestConsensusServersConfig(t *testing.T) {
+	for _, n := range []int{-1, 1} {
+		_, err := NewClient(Config{
+			Address:          "xmr_address",
+			ConsensusServers: n,
+			ServerURLs:       []string{"http://localhost:1", "http://localhost:2"},
+			ViewKey:          "xmr_view_key",
+		})
+		if err != ErrorBadConfig {
+			t.Errorf("NewClient() with %d consensus servers returned the error: %v", n, err)
+		}
+	}
+
+	_, err := NewClient(Config{
+		Address:          "xmr_address",
+		ConsensusServers: 2,
+		ServerURLs:       []string{"http://localhost:1", "http://localhost:2"},
+		ViewKey:          "xmr_view_key",
+	})
+	if err != nil {
+		t.Error("NewClient() returned the error: ", err)
+	}
+}
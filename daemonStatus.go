@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+)
+
+// DaemonStatusResponse describes the Monero daemon
+// a server (eg. monero-lws) scans the blockchain with.
+//
+// State is one of "ok", "no_connections",
+// "synchronizing" or "unavailable".
+type DaemonStatusResponse struct {
+	OutgoingConnections uint64 `json:"outgoing_connections_count"`
+	IncomingConnections uint64 `json:"incoming_connections_count"`
+	Height              uint64 `json:"height"`
+	TargetHeight        uint64 `json:"target_height"`
+	Network             string `json:"network"`
+	State               string `json:"state"`
+}
+
+// Synced reports whether the daemon has caught up with the Monero network.
+func (r *DaemonStatusResponse) Synced() bool {
+	return r.State == "ok"
+}
+
+// DaemonStatus gets the status of the server's Monero daemon.
+//
+// Servers that don't support it return ErrorUnsupported.
+func (c *Client) DaemonStatus() (*DaemonStatusResponse, error) {
+	return c.DaemonStatusContext(context.Background())
+}
+
+// DaemonStatusContext is like DaemonStatus but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (c *Client) DaemonStatusContext(ctx context.Context) (*DaemonStatusResponse, error) {
+	const path = EndpointDaemonStatus
+
+	var response = &DaemonStatusResponse{}
+
+	err := c.post(ctx, path, &struct{}{}, response, ErrorStandardRequestEncode)
+	if err != nil {
+		return &DaemonStatusResponse{}, err
+	}
+
+	return response, nil
+}
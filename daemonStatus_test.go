@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDaemonStatus(t *testing.T) {
+	tryCount := 1 //Number of times to send HTTP Service Unavailable
+
+	response := DaemonStatusResponse{
+		OutgoingConnections: 12,
+		IncomingConnections: 3,
+		Height:              3222300,
+		TargetHeight:        3222370,
+		Network:             "main",
+		State:               "synchronizing",
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != string(EndpointDaemonStatus) {
+			t.Error("DaemonStatus() called ", r.URL.Path)
+		}
+
+		if tryCount != 0 {
+			tryCount--
+
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		err := json.NewEncoder(w).Encode(response)
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:    "xmr_address",
+		client:     &http.Client{},
+		retryCount: tryCount,
+		retryTime:  time.Duration(0),
+		serverURL:  ts.URL,
+		viewKey:    "xmr_view_key",
+	}
+
+	resp, err := client.DaemonStatus()
+	if err != nil {
+		t.Fatal("DaemonStatus() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(*resp, response) {
+		t.Error("response struct didn't match the original data: ", resp)
+	}
+
+	if resp.Synced() {
+		t.Error("Synced() returned true for a synchronizing daemon")
+	}
+}
@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxResponseSize is the largest response we accept, in
+// bytes, from endpoints that aren't in defaultMaxResponseSizes.
+const defaultMaxResponseSize = 1 << 20
+
+// defaultMaxResponseSizes are the largest responses we accept, in
+// bytes, from endpoints whose responses grow with an account's history.
+var defaultMaxResponseSizes = map[Endpoint]int64{
+	EndpointGetAddressInfo: 16 << 20,
+	EndpointGetAddressTxs:  64 << 20,
+	EndpointGetUnspentOuts: 64 << 20,
+	EndpointGetRandomOuts:  16 << 20,
+}
+
+var (
+	ErrorResponseTooLarge     = errors.New("response body is larger than the endpoint's maximum response size")
+	ErrorResponseMalformed    = errors.New("response body is malformed")
+	ErrorResponseTrailingData = errors.New("response body has data after its value")
+	ErrorResponseUnknownField = errors.New("response body has a field we don't know")
+	ErrorResponseTypeMismatch = errors.New("response body has a value of the wrong type")
+)
+
+// DecodeError is returned (as the Cause of an *APIError whose Err is
+// ErrorResponseUnmarshalFailed) when a server's response can't be decoded.
+type DecodeError struct {
+	Endpoint Endpoint // The endpoint we called (eg. "/get_address_txs")
+	Field    string   // The field that couldn't be decoded (eg. "transactions.hash"), if we know it
+	Err      error    // One of the errors above, describing what went wrong
+	Cause    error    // The error from encoding/json or our epee decoder, if there was one
+}
+
+func (e *DecodeError) Error() string {
+	s := e.Err.Error()
+
+	if e.Field != "" {
+		s += " (field " + strconv.Quote(e.Field) + ")"
+	}
+
+	if e.Cause != nil {
+		s += ": " + e.Cause.Error()
+	}
+
+	return s
+}
+
+// Unwrap returns Err and Cause, for use with errors.Is() and errors.As()
+func (e *DecodeError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+
+	return []error{e.Err, e.Cause}
+}
+
+// maxResponseSize returns the largest response we accept from 'endpoint'.
+func (c *Client) maxResponseSize(endpoint Endpoint) int64 {
+	if size, ok := c.maxResponseSizes[endpoint]; ok {
+		return size
+	}
+
+	if size, ok := defaultMaxResponseSizes[endpoint]; ok {
+		return size
+	}
+
+	return defaultMaxResponseSize
+}
+
+// decodeResponse decodes the body of 'resp' into cl.Response, as epee
+// portable storage or JSON depending on its content type. Bodies larger
+// than our maximum response size for cl.Endpoint, or with trailing data,
+// are rejected, as are unknown fields if our client is strict.
+//
+// Errors are returned as a *DecodeError, unless they came from cl.transactions.
+func (c *Client) decodeResponse(resp *http.Response, cl *Call, log *slog.Logger) error {
+	if cl.transactions != nil {
+		return c.streamAddressTxs(resp, cl, log)
+	}
+
+	// The caller doesn't want the response (eg. /add_account's, which can be empty)
+	if cl.Response == nil {
+		return nil
+	}
+
+	limit := c.maxResponseSize(cl.Endpoint)
+
+	if resp.ContentLength > limit {
+		return &DecodeError{Endpoint: cl.Endpoint, Err: ErrorResponseTooLarge}
+	}
+
+	// Read one byte past our limit, so we can tell if it was exceeded
+	b, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return &DecodeError{Endpoint: cl.Endpoint, Err: ErrorResponseMalformed, Cause: err}
+	}
+
+	if int64(len(b)) > limit {
+		return &DecodeError{Endpoint: cl.Endpoint, Err: ErrorResponseTooLarge}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	if mediaType == contentTypeEpee {
+		err = unmarshalEpee(b, cl.Response, c.strictDecoding)
+	} else {
+		err = unmarshalJSON(b, cl.Response, c.strictDecoding)
+	}
+
+	if err != nil {
+		decodeErr := newDecodeError(cl.Endpoint, err)
+
+		log.Error("failed to decode response", slog.String("field", decodeErr.Field), slog.Any("error", decodeErr))
+
+		return decodeErr
+	}
+
+	return nil
+}
+
+// unmarshalJSON decodes the JSON value in 'b' into 'v', rejecting
+// trailing data, and unknown fields if 'strict' is set.
+func unmarshalJSON(b []byte, v interface{}, strict bool) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+
+	err := dec.Decode(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = dec.Token()
+	if err != io.EOF {
+		return errTrailingJSON
+	}
+
+	return nil
+}
+
+// errTrailingJSON is returned by unmarshalJSON when there's data after a value
+var errTrailingJSON = errors.New("json: data after top-level value")
+
+// newDecodeError describes the error 'err' from unmarshalJSON or unmarshalEpee.
+func newDecodeError(endpoint Endpoint, err error) *DecodeError {
+	e := &DecodeError{Endpoint: endpoint, Err: ErrorResponseMalformed, Cause: err}
+
+	var typeErr *json.UnmarshalTypeError
+	var epeeErr *EpeeError
+
+	switch {
+	case errors.As(err, &typeErr):
+		e.Err = ErrorResponseTypeMismatch
+		e.Field = typeErr.Field
+	case errors.As(err, &epeeErr):
+		e.Field = epeeErr.Field
+
+		switch epeeErr.Err {
+		case ErrorEpeeType:
+			e.Err = ErrorResponseTypeMismatch
+		case ErrorEpeeUnknown:
+			e.Err = ErrorResponseUnknownField
+		case ErrorEpeeTrailing:
+			e.Err = ErrorResponseTrailingData
+		}
+	case err == errTrailingJSON:
+		e.Err = ErrorResponseTrailingData
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json doesn't have a type for this error
+		e.Err = ErrorResponseUnknownField
+		e.Field, _ = strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+	}
+
+	return e
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	epee, err := MarshalEpee(struct {
+		BlockchainHeight string `json:"blockchain_height"`
+	}{"3222370"})
+	if err != nil {
+		t.Fatal("MarshalEpee() returned the error: ", err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		strict      bool
+		err         error  // The DecodeError's Err, or nil if decoding should succeed
+		field       string // The DecodeError's Field
+	}{
+		{"valid", contentTypeJSON, `{"blockchain_height": 3222370}`, true, nil, ""},
+		{"unknown field", contentTypeJSON, `{"blockchain_height": 3222370, "extra": 1}`, false, nil, ""},
+		{"strict unknown field", contentTypeJSON, `{"blockchain_height": 3222370, "extra": 1}`, true, ErrorResponseUnknownField, "extra"},
+		{"type mismatch", contentTypeJSON, `{"blockchain_height": "3222370"}`, false, ErrorResponseTypeMismatch, "blockchain_height"},
+		{"trailing data", contentTypeJSON, `{"blockchain_height": 3222370} {}`, false, ErrorResponseTrailingData, ""},
+		{"trailing garbage", contentTypeJSON, `{"blockchain_height": 3222370}garbage`, false, ErrorResponseTrailingData, ""},
+		{"malformed", contentTypeJSON, `{"blockchain_height": `, false, ErrorResponseMalformed, ""},
+		{"too large", contentTypeJSON, `{"blockchain_height": 3222370, "padding": "` + strings.Repeat("a", 1024) + `"}`, false, ErrorResponseTooLarge, ""},
+		{"epee converted", contentTypeEpee, string(epee), false, nil, ""},
+		{"strict epee converted", contentTypeEpee, string(epee), true, ErrorResponseTypeMismatch, "blockchain_height"},
+		{"epee trailing data", contentTypeEpee, string(epee) + "garbage", false, ErrorResponseTrailingData, ""},
+	}
+
+	for _, test := range tests {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", test.contentType)
+
+			_, err := w.Write([]byte(test.body))
+			if err != nil {
+				t.Error("failed to write our response")
+			}
+		}))
+
+		client := &Client{
+			address:          "xmr_address",
+			client:           &http.Client{},
+			maxResponseSizes: map[Endpoint]int64{EndpointGetAddressInfo: 1024},
+			serverURL:        ts.URL,
+			strictDecoding:   test.strict,
+			viewKey:          "xmr_view_key",
+		}
+
+		info, err := client.GetAddressInfo()
+
+		ts.Close()
+
+		if test.err == nil {
+			if err != nil || info.BlockchainHeight != 3222370 {
+				t.Errorf("%s: GetAddressInfo() returned %v, %v", test.name, info, err)
+			}
+
+			continue
+		}
+
+		var decodeErr *DecodeError
+
+		if !errors.Is(err, ErrorResponseUnmarshalFailed) || !errors.As(err, &decodeErr) {
+			t.Errorf("%s: GetAddressInfo() returned the error: %v", test.name, err)
+
+			continue
+		}
+
+		if decodeErr.Err != test.err || decodeErr.Field != test.field || decodeErr.Endpoint != EndpointGetAddressInfo {
+			t.Errorf("%s: GetAddressInfo() returned the error: %#v", test.name, decodeErr)
+		}
+	}
+}
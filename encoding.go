@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+const (
+	contentTypeJSON = "application/json"
+	contentTypeEpee = "application/octet-stream"
+)
+
+// Encoding is the wire format requests are sent in.
+type Encoding int
+
+const (
+	EncodingJSON   Encoding = iota // JSON, which every light wallet server accepts
+	EncodingBinary                 // Epee portable storage (see MarshalEpee), which monero-lws accepts. Falls back to JSON if the server rejects it.
+)
+
+// encodeRequest encodes 'request' as epee portable storage if
+// 'binary' is set, or JSON otherwise, returning its content type.
+func encodeRequest(request interface{}, binary bool) ([]byte, string, error) {
+	if binary {
+		b, err := MarshalEpee(request)
+
+		return b, contentTypeEpee, err
+	}
+
+	b := new(bytes.Buffer)
+
+	err := json.NewEncoder(b).Encode(request)
+
+	return b.Bytes(), contentTypeJSON, err
+}
+
+// binary reports whether our requests should be sent as epee portable storage.
+func (c *Client) binary() bool {
+	return c.encoding == EncodingBinary && !c.binaryRejected.Load()
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEncodingBinary(t *testing.T) {
+	const viewKey = "f359631075708155cc3d92a32b75a7d02a5dcf27756707b47a2b31b21c389501"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != contentTypeEpee {
+			t.Error("the request was sent as ", r.Header.Get("Content-Type"))
+		}
+
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error("failed to read the request: ", err)
+		}
+
+		req := &StandardRequest{}
+
+		err = UnmarshalEpee(b, req)
+		if err != nil || req.ViewKey != viewKey || req.Address != "xmr_address" {
+			t.Error("the server got the request ", req, err)
+		}
+
+		b, err = MarshalEpee(GetAddressInfoResponse{TotalReceived: "31415926535897", BlockchainHeight: 3222370})
+		if err != nil {
+			t.Error("failed to marshal our response: ", err)
+		}
+
+		w.Header().Set("Content-Type", contentTypeEpee)
+		_, _ = w.Write(b)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		Address:   "xmr_address",
+		Encoding:  EncodingBinary,
+		ServerURL: ts.URL,
+		ViewKey:   viewKey,
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	info, err := client.GetAddressInfo()
+	if err != nil {
+		t.Fatal("GetAddressInfo() returned the error: ", err)
+	}
+
+	if info.TotalReceived != "31415926535897" || info.BlockchainHeight != 3222370 {
+		t.Error("GetAddressInfo() returned ", info)
+	}
+}
+
+func TestEncodingBinaryFallback(t *testing.T) {
+	// Servers that don't speak epee reject binary requests in different ways
+	for _, status := range []int{http.StatusUnsupportedMediaType, http.StatusBadRequest, http.StatusInternalServerError} {
+		var contentTypes []string
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+
+			if r.Header.Get("Content-Type") != contentTypeJSON {
+				w.WriteHeader(status)
+
+				return
+			}
+
+			err := json.NewEncoder(w).Encode(GetAddressInfoResponse{BlockchainHeight: 3222370})
+			if err != nil {
+				t.Error("failed to marshal our response")
+			}
+		}))
+
+		client, err := NewClient(Config{
+			Address:   "xmr_address",
+			Encoding:  EncodingBinary,
+			ServerURL: ts.URL,
+			ViewKey:   "f359631075708155cc3d92a32b75a7d02a5dcf27756707b47a2b31b21c389501",
+		})
+		if err != nil {
+			t.Fatal("NewClient() returned the error: ", err)
+		}
+
+		for i := 0; i < 2; i++ {
+			info, err := client.GetAddressInfo()
+			if err != nil || info.BlockchainHeight != 3222370 {
+				t.Fatalf("GetAddressInfo() returned %v, %v after HTTP %d", info, err, status)
+			}
+		}
+
+		ts.Close()
+
+		want := []string{contentTypeEpee, contentTypeJSON, contentTypeJSON}
+
+		if len(contentTypes) != len(want) || contentTypes[0] != want[0] || contentTypes[1] != want[1] || contentTypes[2] != want[2] {
+			t.Errorf("requests were sent as %q after HTTP %d, wanted %q", contentTypes, status, want)
+		}
+	}
+}
+
+func TestEncodingBinaryFallbackFails(t *testing.T) {
+	var contentTypes []string
+
+	// The server speaks epee, but forbids our account
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		Address:   "xmr_address",
+		Encoding:  EncodingBinary,
+		ServerURL: ts.URL,
+		ViewKey:   "f359631075708155cc3d92a32b75a7d02a5dcf27756707b47a2b31b21c389501",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	_, err = client.GetAddressInfo()
+	if !errors.Is(err, ErrorStatusCodeNotOK) {
+		t.Fatal("GetAddressInfo() returned the error: ", err)
+	}
+
+	if !client.binary() || len(contentTypes) != 2 || contentTypes[1] != contentTypeJSON {
+		t.Errorf("requests were sent as %q, and binary was ruled out: %v", contentTypes, !client.binary())
+	}
+}
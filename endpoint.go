@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+// Endpoint is the path of a light wallet API endpoint (eg. "/login")
+type Endpoint string
+
+const (
+	EndpointLogin          Endpoint = "/login"
+	EndpointGetAddressInfo Endpoint = "/get_address_info"
+	EndpointGetAddressTxs  Endpoint = "/get_address_txs"
+	EndpointGetUnspentOuts Endpoint = "/get_unspent_outs"
+	EndpointGetRandomOuts  Endpoint = "/get_random_outs"
+	EndpointImportRequest  Endpoint = "/import_request"
+	EndpointSubmitRawTx    Endpoint = "/submit_raw_tx"
+
+	EndpointGetSubaddrs       Endpoint = "/get_subaddrs"
+	EndpointUpsertSubaddrs    Endpoint = "/upsert_subaddrs"
+	EndpointProvisionSubaddrs Endpoint = "/provision_subaddrs"
+
+	EndpointGetVersion   Endpoint = "/get_version"
+	EndpointDaemonStatus Endpoint = "/daemon_status"
+
+	// monero-lws admin endpoints, see AdminClient
+	EndpointListAccounts        Endpoint = "/list_accounts"
+	EndpointListRequests        Endpoint = "/list_requests"
+	EndpointAcceptRequests      Endpoint = "/accept_requests"
+	EndpointRejectRequests      Endpoint = "/reject_requests"
+	EndpointAddAccount          Endpoint = "/add_account"
+	EndpointModifyAccountStatus Endpoint = "/modify_account_status"
+	EndpointRescan              Endpoint = "/rescan"
+	EndpointWebhookAdd          Endpoint = "/webhook_add"
+	EndpointWebhookDelete       Endpoint = "/webhook_delete"
+	EndpointWebhookDeleteUUID   Endpoint = "/webhook_delete_uuid"
+	EndpointWebhookList         Endpoint = "/webhook_list"
+)
+
+// Idempotent reports whether calling endpoint 'e' more than
+// once has the same effect as calling it once. Endpoints that
+// aren't idempotent (eg. /submit_raw_tx) aren't retried by default.
+func (e Endpoint) Idempotent() bool {
+	switch e {
+	case EndpointSubmitRawTx, EndpointProvisionSubaddrs, EndpointAddAccount, EndpointWebhookAdd:
+		return false
+	}
+
+	return true
+}
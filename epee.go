@@ -0,0 +1,851 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Epee portable storage is the binary format used by Monero's daemon
+// and monero-lws. Values are stored in sections of named entries:
+//
+//	header:  signature A (uint32), signature B (uint32), version (uint8)
+//	section: varint count, then count * (uint8 name length, name, type, value)
+//
+// Arrays are marked by setting epeeArrayFlag on their elements' type,
+// followed by a varint count and the elements without their types.
+// Arrays of arrays have elements of type epeeArray, which are each
+// written as an array: their elements' type, count and elements.
+const (
+	epeeSignatureA = 0x01011101
+	epeeSignatureB = 0x01020101
+	epeeVersion    = 1
+
+	epeeInt64  = 1
+	epeeInt32  = 2
+	epeeInt16  = 3
+	epeeInt8   = 4
+	epeeUint64 = 5
+	epeeUint32 = 6
+	epeeUint16 = 7
+	epeeUint8  = 8
+	epeeDouble = 9
+	epeeString = 10
+	epeeBool   = 11
+	epeeObject = 12
+	epeeArray  = 13 // An array nested in an array
+
+	epeeArrayFlag = 0x80
+
+	// epeeMaxDepth limits how deeply objects and arrays can be nested
+	epeeMaxDepth = 64
+)
+
+var (
+	ErrorEpeeSignature = errors.New("data isn't in epee portable storage format")
+	ErrorEpeeTruncated = errors.New("epee portable storage data ended unexpectedly")
+	ErrorEpeeType      = errors.New("epee portable storage value has the wrong type")
+	ErrorEpeeTrailing  = errors.New("epee portable storage data has trailing bytes")
+	ErrorEpeeUnknown   = errors.New("epee portable storage field is unknown")
+	ErrorEpeeNil       = errors.New("epee portable storage arrays can't hold nil elements")
+)
+
+// EpeeError is returned when a value can't be encoded
+// to, or decoded from, epee portable storage.
+type EpeeError struct {
+	Field string // The name of the field (eg. "transactions.hash"), if any
+	Err   error
+}
+
+func (e *EpeeError) Error() string {
+	if e.Field == "" {
+		return "epee: " + e.Err.Error()
+	}
+
+	return "epee: " + e.Field + ": " + e.Err.Error()
+}
+
+func (e *EpeeError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalEpee encodes the struct 'v' in epee portable storage format.
+//
+// Fields are named by their json tags. Strings holding hex encoded binary
+// (tagged `epee:"hex"`) are stored as raw bytes, strings holding decimal
+// amounts (tagged `epee:"uint64"`) as integers and times as Unix timestamps,
+// matching what monero-lws sends and expects.
+func MarshalEpee(v interface{}) ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, &EpeeError{Err: ErrorEpeeType}
+	}
+
+	b := new(bytes.Buffer)
+
+	_ = binary.Write(b, binary.LittleEndian, uint32(epeeSignatureA))
+	_ = binary.Write(b, binary.LittleEndian, uint32(epeeSignatureB))
+	b.WriteByte(epeeVersion)
+
+	err := writeEpeeSection(b, rv, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// UnmarshalEpee decodes epee portable storage 'data' into the struct 'v' points to.
+//
+// Decoding is lenient, so data encoded with different integer sizes, or with
+// amounts as strings instead of integers, still decodes. Unknown fields are ignored.
+func UnmarshalEpee(data []byte, v interface{}) error {
+	return unmarshalEpee(data, v, false)
+}
+
+// unmarshalEpee is UnmarshalEpee, but if 'strict' is set, unknown fields
+// and values that don't have exactly the type a field is encoded as are rejected.
+func unmarshalEpee(data []byte, v interface{}, strict bool) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &EpeeError{Err: ErrorEpeeType}
+	}
+
+	r := &epeeReader{data: data}
+
+	a, errA := r.uint32()
+	b, errB := r.uint32()
+	version, errV := r.byte()
+	if errA != nil || errB != nil || errV != nil || a != epeeSignatureA || b != epeeSignatureB || version != epeeVersion {
+		return &EpeeError{Err: ErrorEpeeSignature}
+	}
+
+	section, err := r.section(0)
+	if err != nil {
+		return &EpeeError{Err: err}
+	}
+
+	if r.pos != len(data) {
+		return &EpeeError{Err: ErrorEpeeTrailing}
+	}
+
+	return epeeDecoder{strict: strict}.assign(rv.Elem(), section, epeeOptions{}, "")
+}
+
+// epeeOptions are the options set in a field's epee tag
+type epeeOptions struct {
+	hex     bool // The field is a hex encoded string, stored as raw bytes
+	decimal bool // The field is a decimal string, stored as a uint64
+}
+
+// epeeField is an exported struct field and its epee name and options
+type epeeField struct {
+	index   int
+	name    string
+	options epeeOptions
+}
+
+// epeeFields returns the fields of struct type 't' that are encoded.
+func epeeFields(t reflect.Type) []epeeField {
+	var fields []epeeField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		var options epeeOptions
+
+		switch f.Tag.Get("epee") {
+		case "hex":
+			options.hex = true
+		case "uint64":
+			options.decimal = true
+		}
+
+		fields = append(fields, epeeField{index: i, name: name, options: options})
+	}
+
+	return fields
+}
+
+func writeEpeeSection(b *bytes.Buffer, rv reflect.Value, path string) error {
+	fields := epeeFields(rv.Type())
+
+	// Nil pointers and slices are left out, like optional fields
+	var present []epeeField
+	for _, f := range fields {
+		fv := rv.Field(f.index)
+		if (fv.Kind() != reflect.Pointer && fv.Kind() != reflect.Slice) || !fv.IsNil() {
+			present = append(present, f)
+		}
+	}
+
+	writeEpeeVarint(b, uint64(len(present)))
+
+	for _, f := range present {
+		if len(f.name) > math.MaxUint8 {
+			return &EpeeError{Field: path + f.name, Err: ErrorEpeeType}
+		}
+
+		b.WriteByte(byte(len(f.name)))
+		b.WriteString(f.name)
+
+		fv := reflect.Indirect(rv.Field(f.index))
+
+		err := writeEpeeEntry(b, fv, f.options, path+f.name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeEpeeEntry writes the type of 'rv' followed by its value.
+func writeEpeeEntry(b *bytes.Buffer, rv reflect.Value, options epeeOptions, path string) error {
+	if isEpeeArray(rv.Type()) {
+		return writeEpeeArray(b, rv, options, path)
+	}
+
+	t, err := epeeType(rv.Type(), options)
+	if err != nil {
+		return &EpeeError{Field: path, Err: err}
+	}
+
+	b.WriteByte(t)
+
+	return writeEpeeValue(b, rv, options, path)
+}
+
+// isEpeeArray reports whether values of Go type 't' are stored as an array.
+func isEpeeArray(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return true
+	}
+
+	return false
+}
+
+// writeEpeeArray writes the type of the elements in slice (or array) 'rv',
+// its length, and its elements.
+func writeEpeeArray(b *bytes.Buffer, rv reflect.Value, options epeeOptions, path string) error {
+	t, err := epeeType(rv.Type().Elem(), options)
+	if err != nil {
+		return &EpeeError{Field: path, Err: err}
+	}
+
+	b.WriteByte(t | epeeArrayFlag)
+	writeEpeeVarint(b, uint64(rv.Len()))
+
+	for i := 0; i < rv.Len(); i++ {
+		ev := rv.Index(i)
+		if ev.Kind() == reflect.Pointer && ev.IsNil() {
+			return &EpeeError{Field: path, Err: ErrorEpeeNil}
+		}
+
+		err = writeEpeeValue(b, reflect.Indirect(ev), options, path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// epeeType returns the epee type values of Go type 't' are stored as.
+func epeeType(t reflect.Type, options epeeOptions) (byte, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return epeeUint64, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64:
+		return epeeInt64, nil
+	case reflect.Int32:
+		return epeeInt32, nil
+	case reflect.Int16:
+		return epeeInt16, nil
+	case reflect.Int8:
+		return epeeInt8, nil
+	case reflect.Uint, reflect.Uint64:
+		return epeeUint64, nil
+	case reflect.Uint32:
+		return epeeUint32, nil
+	case reflect.Uint16:
+		return epeeUint16, nil
+	case reflect.Uint8:
+		return epeeUint8, nil
+	case reflect.Float32, reflect.Float64:
+		return epeeDouble, nil
+	case reflect.String:
+		if options.decimal {
+			return epeeUint64, nil
+		}
+
+		return epeeString, nil
+	case reflect.Bool:
+		return epeeBool, nil
+	case reflect.Struct:
+		return epeeObject, nil
+	case reflect.Slice, reflect.Array:
+		if isEpeeArray(t) {
+			return epeeArray, nil
+		}
+
+		return epeeString, nil
+	}
+
+	return 0, ErrorEpeeType
+}
+
+// writeEpeeValue writes the value of 'rv', without its type.
+func writeEpeeValue(b *bytes.Buffer, rv reflect.Value, options epeeOptions, path string) error {
+	if t, ok := rv.Interface().(time.Time); ok {
+		_ = binary.Write(b, binary.LittleEndian, uint64(t.Unix()))
+
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int64:
+		_ = binary.Write(b, binary.LittleEndian, rv.Int())
+	case reflect.Int32:
+		_ = binary.Write(b, binary.LittleEndian, int32(rv.Int()))
+	case reflect.Int16:
+		_ = binary.Write(b, binary.LittleEndian, int16(rv.Int()))
+	case reflect.Int8:
+		b.WriteByte(byte(rv.Int()))
+	case reflect.Uint, reflect.Uint64:
+		_ = binary.Write(b, binary.LittleEndian, rv.Uint())
+	case reflect.Uint32:
+		_ = binary.Write(b, binary.LittleEndian, uint32(rv.Uint()))
+	case reflect.Uint16:
+		_ = binary.Write(b, binary.LittleEndian, uint16(rv.Uint()))
+	case reflect.Uint8:
+		b.WriteByte(byte(rv.Uint()))
+	case reflect.Float32, reflect.Float64:
+		_ = binary.Write(b, binary.LittleEndian, rv.Float())
+	case reflect.Bool:
+		if rv.Bool() {
+			b.WriteByte(1)
+		} else {
+			b.WriteByte(0)
+		}
+	case reflect.String:
+		s := rv.String()
+
+		switch {
+		case options.decimal:
+			n := uint64(0)
+
+			if s != "" {
+				var err error
+
+				n, err = strconv.ParseUint(s, 10, 64)
+				if err != nil {
+					return &EpeeError{Field: path, Err: err}
+				}
+			}
+
+			_ = binary.Write(b, binary.LittleEndian, n)
+
+			return nil
+		case options.hex:
+			raw, err := hex.DecodeString(s)
+			if err != nil {
+				return &EpeeError{Field: path, Err: err}
+			}
+
+			s = string(raw)
+		}
+
+		writeEpeeVarint(b, uint64(len(s)))
+		b.WriteString(s)
+	case reflect.Slice, reflect.Array:
+		if isEpeeArray(rv.Type()) {
+			return writeEpeeArray(b, rv, options, path)
+		}
+
+		writeEpeeVarint(b, uint64(rv.Len()))
+		b.Write(rv.Bytes())
+	case reflect.Struct:
+		return writeEpeeSection(b, rv, path+".")
+	default:
+		return &EpeeError{Field: path, Err: ErrorEpeeType}
+	}
+
+	return nil
+}
+
+// writeEpeeVarint writes 'n' with its size in its lowest two bits.
+func writeEpeeVarint(b *bytes.Buffer, n uint64) {
+	switch {
+	case n <= math.MaxUint8>>2:
+		b.WriteByte(byte(n << 2))
+	case n <= math.MaxUint16>>2:
+		_ = binary.Write(b, binary.LittleEndian, uint16(n<<2|1))
+	case n <= math.MaxUint32>>2:
+		_ = binary.Write(b, binary.LittleEndian, uint32(n<<2|2))
+	default:
+		_ = binary.Write(b, binary.LittleEndian, n<<2|3)
+	}
+}
+
+// epeeReader decodes epee portable storage into maps
+// (for objects), slices (for arrays), and Go values.
+type epeeReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *epeeReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.data)-r.pos < n {
+		return nil, ErrorEpeeTruncated
+	}
+
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+
+	return b, nil
+}
+
+func (r *epeeReader) byte() (byte, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return 0, err
+	}
+
+	return b[0], nil
+}
+
+func (r *epeeReader) uint32() (uint32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (r *epeeReader) varint() (uint64, error) {
+	first, err := r.byte()
+	if err != nil {
+		return 0, err
+	}
+
+	size := 1 << (first & 3)
+	r.pos--
+
+	b, err := r.next(size)
+	if err != nil {
+		return 0, err
+	}
+
+	var n uint64
+	for i := size - 1; i >= 0; i-- {
+		n = n<<8 | uint64(b[i])
+	}
+
+	return n >> 2, nil
+}
+
+// length reads a varint count of things that each take at least one byte.
+func (r *epeeReader) length() (int, error) {
+	n, err := r.varint()
+	if err != nil {
+		return 0, err
+	}
+
+	if n > uint64(len(r.data)-r.pos) {
+		return 0, ErrorEpeeTruncated
+	}
+
+	return int(n), nil
+}
+
+func (r *epeeReader) section(depth int) (map[string]interface{}, error) {
+	if depth > epeeMaxDepth {
+		return nil, ErrorEpeeType
+	}
+
+	count, err := r.length()
+	if err != nil {
+		return nil, err
+	}
+
+	section := map[string]interface{}{}
+
+	for i := 0; i < count; i++ {
+		n, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := r.next(int(n))
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+
+		if t&epeeArrayFlag != 0 {
+			section[string(name)], err = r.array(t&^epeeArrayFlag, depth)
+		} else {
+			section[string(name)], err = r.value(t, depth)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return section, nil
+}
+
+func (r *epeeReader) array(t byte, depth int) ([]interface{}, error) {
+	count, err := r.length()
+	if err != nil {
+		return nil, err
+	}
+
+	// Counts come from the server, so elements are only kept once they're read
+	array := []interface{}{}
+
+	for i := 0; i < count; i++ {
+		v, err := r.value(t, depth)
+		if err != nil {
+			return nil, err
+		}
+
+		array = append(array, v)
+	}
+
+	return array, nil
+}
+
+// value reads a value of type 't', returning it as an int64, uint64,
+// float64, string, bool, or map[string]interface{} (for objects).
+func (r *epeeReader) value(t byte, depth int) (interface{}, error) {
+	sizes := map[byte]int{
+		epeeInt64: 8, epeeInt32: 4, epeeInt16: 2, epeeInt8: 1,
+		epeeUint64: 8, epeeUint32: 4, epeeUint16: 2, epeeUint8: 1,
+		epeeDouble: 8, epeeBool: 1,
+	}
+
+	switch t {
+	case epeeString:
+		n, err := r.length()
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := r.next(n)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(b), nil
+	case epeeObject:
+		return r.section(depth + 1)
+	case epeeArray:
+		t, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+
+		if t&epeeArrayFlag == 0 || depth >= epeeMaxDepth {
+			return nil, ErrorEpeeType
+		}
+
+		return r.array(t&^epeeArrayFlag, depth+1)
+	}
+
+	size, ok := sizes[t]
+	if !ok {
+		return nil, ErrorEpeeType
+	}
+
+	b, err := r.next(size)
+	if err != nil {
+		return nil, err
+	}
+
+	var n uint64
+	for i := size - 1; i >= 0; i-- {
+		n = n<<8 | uint64(b[i])
+	}
+
+	switch t {
+	case epeeInt64:
+		return int64(n), nil
+	case epeeInt32:
+		return int64(int32(n)), nil
+	case epeeInt16:
+		return int64(int16(n)), nil
+	case epeeInt8:
+		return int64(int8(n)), nil
+	case epeeDouble:
+		return math.Float64frombits(n), nil
+	case epeeBool:
+		return n != 0, nil
+	}
+
+	return n, nil
+}
+
+// epeeDecoder stores values read by an epeeReader in Go values
+type epeeDecoder struct {
+	strict bool // Reject unknown fields and values that need converting
+}
+
+// assign stores the decoded value 'v' in 'rv'.
+func (d epeeDecoder) assign(rv reflect.Value, v interface{}, options epeeOptions, path string) error {
+	mismatch := &EpeeError{Field: path, Err: ErrorEpeeType}
+
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Type() == reflect.TypeOf(time.Time{}) {
+		switch v := v.(type) {
+		case uint64:
+			rv.Set(reflect.ValueOf(time.Unix(int64(v), 0).UTC()))
+		case int64:
+			rv.Set(reflect.ValueOf(time.Unix(v, 0).UTC()))
+		case string:
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return &EpeeError{Field: path, Err: err}
+			}
+
+			rv.Set(reflect.ValueOf(t))
+		default:
+			return mismatch
+		}
+
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8:
+		var n int64
+
+		switch v := v.(type) {
+		case int64:
+			n = v
+		case uint64:
+			if v > math.MaxInt64 || d.strict {
+				return mismatch
+			}
+
+			n = int64(v)
+		default:
+			return mismatch
+		}
+
+		if rv.OverflowInt(n) {
+			return mismatch
+		}
+
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		var n uint64
+
+		switch v := v.(type) {
+		case uint64:
+			n = v
+		case int64:
+			if v < 0 || d.strict {
+				return mismatch
+			}
+
+			n = uint64(v)
+		case string:
+			if d.strict {
+				return mismatch
+			}
+
+			var err error
+
+			n, err = strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return mismatch
+			}
+		default:
+			return mismatch
+		}
+
+		if rv.OverflowUint(n) {
+			return mismatch
+		}
+
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		switch v := v.(type) {
+		case float64:
+			rv.SetFloat(v)
+		case int64:
+			if d.strict {
+				return mismatch
+			}
+
+			rv.SetFloat(float64(v))
+		case uint64:
+			if d.strict {
+				return mismatch
+			}
+
+			rv.SetFloat(float64(v))
+		default:
+			return mismatch
+		}
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return mismatch
+		}
+
+		rv.SetBool(b)
+	case reflect.String:
+		switch v := v.(type) {
+		case string:
+			if d.strict && options.decimal {
+				return mismatch
+			}
+
+			if options.hex {
+				v = hex.EncodeToString([]byte(v))
+			}
+
+			rv.SetString(v)
+		case uint64:
+			if d.strict && !options.decimal {
+				return mismatch
+			}
+
+			rv.SetString(strconv.FormatUint(v, 10))
+		case int64:
+			if d.strict {
+				return mismatch
+			}
+
+			rv.SetString(strconv.FormatInt(v, 10))
+		default:
+			return mismatch
+		}
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			s, ok := v.(string)
+			if !ok {
+				return mismatch
+			}
+
+			rv.SetBytes([]byte(s))
+
+			return nil
+		}
+
+		array, ok := v.([]interface{})
+		if !ok {
+			return mismatch
+		}
+
+		slice := reflect.MakeSlice(rv.Type(), len(array), len(array))
+
+		for i, elem := range array {
+			err := d.assign(slice.Index(i), elem, options, path)
+			if err != nil {
+				return err
+			}
+		}
+
+		rv.Set(slice)
+	case reflect.Array:
+		array, ok := v.([]interface{})
+		if !ok || len(array) != rv.Len() {
+			return mismatch
+		}
+
+		for i, elem := range array {
+			err := d.assign(rv.Index(i), elem, options, path)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		section, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+
+		prefix := path
+		if prefix != "" {
+			prefix += "."
+		}
+
+		fields := epeeFields(rv.Type())
+
+		if d.strict {
+			known := map[string]bool{}
+			for _, f := range fields {
+				known[f.name] = true
+			}
+
+			for name := range section {
+				if !known[name] {
+					return &EpeeError{Field: prefix + name, Err: ErrorEpeeUnknown}
+				}
+			}
+		}
+
+		for _, f := range fields {
+			value, ok := section[f.name]
+			if !ok {
+				continue
+			}
+
+			err := d.assign(rv.Field(f.index), value, f.options, prefix+f.name)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestMarshalEpee(t *testing.T) {
+	b, err := MarshalEpee(&GetRandomOutsRequest{Count: 2, Amounts: []string{"300"}})
+	if err != nil {
+		t.Fatal("MarshalEpee() returned the error: ", err)
+	}
+
+	want := []byte{
+		0x01, 0x11, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01, // Header
+		2 << 2, // Two entries
+		5, 'c', 'o', 'u', 'n', 't', epeeUint32, 2, 0, 0, 0,
+		7, 'a', 'm', 'o', 'u', 'n', 't', 's', epeeUint64 | epeeArrayFlag, 1 << 2, 0x2c, 0x01, 0, 0, 0, 0, 0, 0,
+	}
+
+	if !bytes.Equal(b, want) {
+		t.Errorf("MarshalEpee() returned\n%x, wanted\n%x", b, want)
+	}
+}
+
+func TestEpeeRoundTrip(t *testing.T) {
+	response := GetAddressTxsResponse{
+		TotalReceived:      "31415926535897",
+		ScannedHeight:      3222370,
+		ScannedBlockHeight: 3222370,
+		StartHeight:        3222370,
+		BlockchainHeight:   3222370,
+		Transactions: []Transaction{{
+			ID:            7,
+			Hash:          "a70d679d2052f752732659680f27afe54b83686866c906cb5b4d9c91ce65a942",
+			Timestamp:     time.Unix(1700000000, 0).UTC(),
+			TotalReceived: "31415926535897",
+			TotalSent:     "0",
+			Height:        3222370,
+			SpentOutputs: []Spend{{
+				Amount:      "31415926535897",
+				KeyImage:    "a555554cd552acb3554caaca94914f54a5567946",
+				TxPublicKey: "a06c7f33eb578148a578167babf3367f87a43ee601d9feda98c803c135c0b506",
+				Mixin:       15,
+			}},
+			PaymentID: "",
+			Mempool:   true,
+		}},
+	}
+
+	b, err := MarshalEpee(response)
+	if err != nil {
+		t.Fatal("MarshalEpee() returned the error: ", err)
+	}
+
+	decoded := GetAddressTxsResponse{}
+
+	err = UnmarshalEpee(b, &decoded)
+	if err != nil {
+		t.Fatal("UnmarshalEpee() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(decoded, response) {
+		t.Errorf("UnmarshalEpee() returned\n%+v, wanted\n%+v", decoded, response)
+	}
+	// IndexRanges are arrays nested in arrays
+	subaddrs := UpsertSubaddrsResponse{
+		NewSubaddrs: []Subaddrs{{Major: 1, Ranges: []IndexRange{{1, 99}, {200, 299}}}},
+	}
+
+	b, err = MarshalEpee(subaddrs)
+	if err != nil {
+		t.Fatal("MarshalEpee() returned the error: ", err)
+	}
+
+	decodedSubaddrs := UpsertSubaddrsResponse{}
+
+	err = UnmarshalEpee(b, &decodedSubaddrs)
+	if err != nil {
+		t.Fatal("UnmarshalEpee() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(decodedSubaddrs, subaddrs) {
+		t.Errorf("UnmarshalEpee() returned\n%+v, wanted\n%+v", decodedSubaddrs, subaddrs)
+	}
+}
+
+func TestUnmarshalEpeeErrors(t *testing.T) {
+	b, err := MarshalEpee(&GetRandomOutsRequest{Count: 2, Amounts: []string{"300"}})
+	if err != nil {
+		t.Fatal("MarshalEpee() returned the error: ", err)
+	}
+
+	err = UnmarshalEpee([]byte(`{"count":2}`), &GetRandomOutsRequest{})
+	if !errors.Is(err, ErrorEpeeSignature) {
+		t.Error("UnmarshalEpee() decoded JSON: ", err)
+	}
+
+	for i := 10; i < len(b); i++ {
+		err = UnmarshalEpee(b[:i], &GetRandomOutsRequest{})
+		if !errors.Is(err, ErrorEpeeTruncated) {
+			t.Errorf("UnmarshalEpee() decoded %d of %d bytes: %v", i, len(b), err)
+		}
+	}
+
+	// "amounts" can't be decoded into a bool
+	var wrong struct {
+		Amounts []bool `json:"amounts"`
+	}
+
+	var epeeErr *EpeeError
+
+	err = UnmarshalEpee(b, &wrong)
+	if !errors.As(err, &epeeErr) || epeeErr.Field != "amounts" || !errors.Is(err, ErrorEpeeType) {
+		t.Error("UnmarshalEpee() returned the error: ", err)
+	}
+}
+
+func TestMarshalEpeeNilElement(t *testing.T) {
+	var v struct {
+		Outputs []*Output `json:"outputs"`
+	}
+
+	v.Outputs = []*Output{{Amount: "1"}, nil}
+
+	var epeeErr *EpeeError
+
+	_, err := MarshalEpee(&v)
+	if !errors.As(err, &epeeErr) || epeeErr.Field != "outputs" || !errors.Is(err, ErrorEpeeNil) {
+		t.Error("MarshalEpee() returned the error: ", err)
+	}
+}
+
+func TestUnmarshalEpeeArrayCount(t *testing.T) {
+	const count = 1 << 20
+
+	// An array claiming a million objects, whose first one is garbage
+	b := bytes.NewBuffer([]byte{0x01, 0x11, 0x01, 0x01, 0x01, 0x01, 0x02, 0x01, 0x01})
+	writeEpeeVarint(b, 1)
+	b.Write([]byte{1, 'a', epeeObject | epeeArrayFlag})
+	writeEpeeVarint(b, count)
+	b.Write(bytes.Repeat([]byte{0xff}, count))
+
+	var before, after runtime.MemStats
+
+	runtime.ReadMemStats(&before)
+
+	err := UnmarshalEpee(b.Bytes(), &struct{}{})
+
+	runtime.ReadMemStats(&after)
+
+	if !errors.Is(err, ErrorEpeeTruncated) {
+		t.Error("UnmarshalEpee() returned the error: ", err)
+	}
+
+	// Preallocating the array would take 16 MiB
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > count {
+		t.Errorf("UnmarshalEpee() allocated %d bytes for a %d byte array", allocated, count)
+	}
+}
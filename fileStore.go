@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// FileStore is a Store saving each account's
+// SyncState as a JSON file in a directory.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a FileStore saving states in 'dir',
+// which is created (with its parents) if it doesn't exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+// Load implements Store
+func (s *FileStore) Load(ctx context.Context, address string) (*SyncState, error) {
+	b, err := os.ReadFile(s.path(address))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	state := &SyncState{}
+
+	err = json.Unmarshal(b, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Save implements Store. States are written to a temporary
+// file first, so a crash never leaves a partial state behind.
+func (s *FileStore) Save(ctx context.Context, state *SyncState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.dir, ".sync-*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path(state.Address))
+}
+
+// path returns the file the state for 'address' is saved in
+func (s *FileStore) path(address string) string {
+	return filepath.Join(s.dir, url.PathEscape(address)+".json")
+}
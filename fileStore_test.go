@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "sync"))
+	if err != nil {
+		t.Fatal("NewFileStore() returned the error: ", err)
+	}
+
+	state, err := store.Load(ctx, "xmr_address")
+	if state != nil || err != nil {
+		t.Error("Load() returned a state that wasn't saved: ", state, err)
+	}
+
+	saved := &SyncState{
+		Address:          "xmr/address",
+		ScannedHeight:    3222360,
+		BlockchainHeight: 3222370,
+		Transactions: map[string]Transaction{
+			"aa": {Hash: "aa", Height: 3222300, Timestamp: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), TotalReceived: "314159"},
+		},
+	}
+
+	err = store.Save(ctx, saved)
+	if err != nil {
+		t.Fatal("Save() returned the error: ", err)
+	}
+
+	state, err = store.Load(ctx, "xmr/address")
+	if err != nil || !reflect.DeepEqual(state, saved) {
+		t.Errorf("Load() returned: %+v, %v", state, err)
+	}
+
+	// Addresses can't escape the store's directory, and no temporary files are left behind
+	entries, _ := os.ReadDir(store.dir)
+	if len(entries) != 1 || entries[0].Name() != "xmr%2Faddress.json" {
+		t.Error("the store's directory had the files: ", entries)
+	}
+}
+
+func TestFileStoreResume(t *testing.T) {
+	txs := []Transaction{
+		{Hash: "aa", Height: 3222300},
+		{Hash: "bb", Height: 3222371, Mempool: true},
+	}
+
+	ts := newSyncServer(t, &txs)
+	defer ts.Close()
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal("NewFileStore() returned the error: ", err)
+	}
+
+	_, err = client.NewSyncer(store).Refresh(context.Background())
+	if err != nil {
+		t.Fatal("Refresh() returned the error: ", err)
+	}
+
+	txs[1].Mempool = false
+
+	// A restarted service only sees what changed since it stopped
+	store, err = NewFileStore(dir)
+	if err != nil {
+		t.Fatal("NewFileStore() returned the error: ", err)
+	}
+
+	diff, err := client.NewSyncer(store).Refresh(context.Background())
+	if err != nil {
+		t.Fatal("Refresh() returned the error: ", err)
+	}
+
+	if len(diff.New) != 0 || len(diff.Confirmed) != 1 || diff.Confirmed[0].Hash != "bb" {
+		t.Errorf("Refresh() returned: %+v", diff)
+	}
+}
@@ -4,13 +4,7 @@
 package gomonerolight
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"net/url"
-	"os"
-	"time"
+	"context"
 )
 
 // GetAddressInfoResponse holds the information to calculate
@@ -18,9 +12,9 @@ import (
 //
 // ExchangeRates is optional and may not be sent by the server.
 type GetAddressInfoResponse struct {
-	LockedFunds        string  `json:"locked_funds"`
-	TotalReceived      string  `json:"total_received"`
-	TotalSent          string  `json:"total_sent"`
+	LockedFunds        string  `json:"locked_funds" epee:"uint64"`
+	TotalReceived      string  `json:"total_received" epee:"uint64"`
+	TotalSent          string  `json:"total_sent" epee:"uint64"`
 	ScannedHeight      uint64  `json:"scanned_height"`
 	ScannedBlockHeight uint64  `json:"scanned_block_height"`
 	StartHeight        uint64  `json:"start_height"`
@@ -60,59 +54,24 @@ type Rates struct {
 // The server returns candidate spends that can be used to calculate
 // a wallet's balance using our spend key.
 func (c *Client) GetAddressInfo() (*GetAddressInfoResponse, error) {
-	const path = "/get_address_info"
+	return c.GetAddressInfoContext(context.Background())
+}
 
-	b := new(bytes.Buffer)
+// GetAddressInfoContext is like GetAddressInfo but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (c *Client) GetAddressInfoContext(ctx context.Context) (*GetAddressInfoResponse, error) {
+	const path = EndpointGetAddressInfo
 
 	request := &StandardRequest{
 		Address: c.address,
 		ViewKey: c.viewKey,
 	}
 
-	err := json.NewEncoder(b).Encode(request)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to encode:\n\n%#v\n\nwith error:\n%v\n\n", *request, err)
-
-		return &GetAddressInfoResponse{}, ErrorStandardRequestEncode
-	}
-
-	url, err := url.JoinPath(c.serverURL, path)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to join:\n%s\nand\n%s\nwith error:\n\n%v\n\n", c.serverURL, path, err)
-
-		return &GetAddressInfoResponse{}, ErrorJoinPathFailed
-	}
-
-	retries := 0
-
-POST_REQUEST:
-
-	resp, err := c.client.Post(url, "application/json", bytes.NewReader(b.Bytes()))
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to post:\n\n%s\n\n to our endpoint at:\n\n%s\n\nwith error:\n\n%v\n\n", b.String(), url, err)
-
-		return &GetAddressInfoResponse{}, ErrorPostRequestFailed
-	}
-
-	if resp.StatusCode == http.StatusServiceUnavailable {
-		if retries < c.retryCount {
-			time.Sleep(c.retryTime)
-
-			goto POST_REQUEST
-		}
-
-		return &GetAddressInfoResponse{}, ErrorServiceUnavailable
-	} else if resp.StatusCode != http.StatusOK {
-		return &GetAddressInfoResponse{}, ErrorStatusCodeNotOK
-	}
-
 	var response = &GetAddressInfoResponse{}
 
-	err = json.NewDecoder(resp.Body).Decode(response)
+	err := c.post(ctx, path, request, response, ErrorStandardRequestEncode)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to decode:\n\n%#v\n\nwith error:\n%v\n", response, err)
-
-		return &GetAddressInfoResponse{}, ErrorResponseUnmarshalFailed
+		return &GetAddressInfoResponse{}, err
 	}
 
 	return response, nil
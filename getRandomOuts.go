@@ -4,14 +4,8 @@
 package gomonerolight
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
-	"fmt"
-	"net/http"
-	"net/url"
-	"os"
-	"time"
 )
 
 // GetRandomOutsRequest holds request data for GetRandomOuts()
@@ -21,7 +15,7 @@ import (
 // https://github.com/monero-project/meta/blob/master/api/lightwallet_rest.md#get_random_outs
 type GetRandomOutsRequest struct {
 	Count   uint32   `json:"count"`
-	Amounts []string `json:"amounts"`
+	Amounts []string `json:"amounts" epee:"uint64"`
 }
 
 // GetRandomOutsResponse
@@ -30,14 +24,14 @@ type GetRandomOutsResponse struct {
 }
 
 type RandomOutputs struct {
-	Amount  string         `json:"amount"`
+	Amount  string         `json:"amount" epee:"uint64"`
 	Outputs []RandomOutput `json:"outputs"`
 }
 
 type RandomOutput struct {
-	GlobalIndex string `json:"global_index"`
-	PublicKey   string `json:"public_key"`
-	RingCT      string `json:"rct"`
+	GlobalIndex string `json:"global_index" epee:"uint64"`
+	PublicKey   string `json:"public_key" epee:"hex"`
+	RingCT      string `json:"rct" epee:"hex"`
 }
 
 var ErrorRandomOutsRequestEncode = errors.New("failed to encode random outs request using data from 'request' and 'client'")
@@ -45,54 +39,19 @@ var ErrorRandomOutsRequestEncode = errors.New("failed to encode random outs requ
 // GetRandomOuts selects random outputs to be
 // used for a ring signature in a new transaction.
 func (c *Client) GetRandomOuts(request *GetRandomOutsRequest) (*GetRandomOutsResponse, error) {
-	const path = "/get_random_outs"
-
-	b := new(bytes.Buffer)
-
-	err := json.NewEncoder(b).Encode(request)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to encode:\n\n%#v\n\nwith error:\n%v\n\n", *request, err)
-
-		return &GetRandomOutsResponse{}, ErrorRandomOutsRequestEncode
-	}
-
-	url, err := url.JoinPath(c.serverURL, path)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to join:\n%s\nand\n%s\nwith error:\n\n%v\n\n", c.serverURL, path, err)
-
-		return &GetRandomOutsResponse{}, ErrorJoinPathFailed
-	}
-
-	retries := 0
-
-POST_REQUEST:
-
-	resp, err := c.client.Post(url, "application/json", bytes.NewReader(b.Bytes()))
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to post:\n\n%s\n\n to our endpoint at:\n\n%s\n\nwith error:\n\n%v\n\n", b.String(), url, err)
-
-		return &GetRandomOutsResponse{}, ErrorPostRequestFailed
-	}
-
-	if resp.StatusCode == http.StatusServiceUnavailable {
-		if retries < c.retryCount {
-			time.Sleep(c.retryTime)
+	return c.GetRandomOutsContext(context.Background(), request)
+}
 
-			goto POST_REQUEST
-		}
-
-		return &GetRandomOutsResponse{}, ErrorServiceUnavailable
-	} else if resp.StatusCode != http.StatusOK {
-		return &GetRandomOutsResponse{}, ErrorStatusCodeNotOK
-	}
+// GetRandomOutsContext is like GetRandomOuts but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (c *Client) GetRandomOutsContext(ctx context.Context, request *GetRandomOutsRequest) (*GetRandomOutsResponse, error) {
+	const path = EndpointGetRandomOuts
 
 	var response = &GetRandomOutsResponse{}
 
-	err = json.NewDecoder(resp.Body).Decode(response)
+	err := c.post(ctx, path, request, response, ErrorRandomOutsRequestEncode)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to decode:\n\n%#v\n\nwith error:\n%v\n", response, err)
-
-		return &GetRandomOutsResponse{}, ErrorResponseUnmarshalFailed
+		return &GetRandomOutsResponse{}, err
 	}
 
 	return response, nil
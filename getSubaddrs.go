@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+)
+
+// GetSubaddrsResponse lists every subaddress
+// the server is scanning for our account.
+type GetSubaddrsResponse struct {
+	AllSubaddrs []Subaddrs `json:"all_subaddrs"`
+}
+
+// GetSubaddrs gets the ranges of subaddresses the server
+// looks for when scanning transactions for our account.
+func (c *Client) GetSubaddrs() (*GetSubaddrsResponse, error) {
+	return c.GetSubaddrsContext(context.Background())
+}
+
+// GetSubaddrsContext is like GetSubaddrs but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (c *Client) GetSubaddrsContext(ctx context.Context) (*GetSubaddrsResponse, error) {
+	const path = EndpointGetSubaddrs
+
+	request := &StandardRequest{
+		Address: c.address,
+		ViewKey: c.viewKey,
+	}
+
+	var response = &GetSubaddrsResponse{}
+
+	err := c.post(ctx, path, request, response, ErrorStandardRequestEncode)
+	if err != nil {
+		return &GetSubaddrsResponse{}, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetSubaddrs(t *testing.T) {
+	tryCount := 1 //Number of times to send HTTP Service Unavailable
+
+	request := &StandardRequest{
+		Address: "xmr_address",
+		ViewKey: "xmr_view_key",
+	}
+
+	response := GetSubaddrsResponse{
+		AllSubaddrs: []Subaddrs{
+			{Major: 0, Ranges: []IndexRange{{1, 99}, {200, 299}}},
+			{Major: 1, Ranges: []IndexRange{{0, 9}}},
+		},
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != string(EndpointGetSubaddrs) {
+			t.Error("GetSubaddrs() called ", r.URL.Path)
+		}
+
+		var req = &StandardRequest{}
+
+		err := json.NewDecoder(r.Body).Decode(req)
+		if err != nil {
+			t.Error("GetSubaddrs() made an invalid request: ", err)
+		}
+
+		if !reflect.DeepEqual(req, request) {
+			t.Error("req struct didn't match the original data in request")
+		}
+
+		if tryCount != 0 {
+			tryCount--
+
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		// IndexRanges are sent as arrays, eg. [[1, 99], [200, 299]]
+		_, err = w.Write([]byte(`{"all_subaddrs":[{"key":0,"value":[[1,99],[200,299]]},{"key":1,"value":[[0,9]]}]}`))
+		if err != nil {
+			t.Error("failed to write our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:    request.Address,
+		client:     &http.Client{},
+		retryCount: tryCount,
+		retryTime:  time.Duration(0),
+		serverURL:  ts.URL,
+		viewKey:    request.ViewKey,
+	}
+
+	resp, err := client.GetSubaddrs()
+	if err != nil {
+		t.Fatal("GetSubaddrs() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(*resp, response) {
+		t.Error("response struct didn't match the original data: ", resp)
+	}
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+)
+
+// GetVersionResponse describes the server software, as reported
+// by monero-lws. Other servers may not support /get_version.
+//
+// API holds the server's API version, with the major
+// version in its upper 16 bits and the minor in its lower 16.
+type GetVersionResponse struct {
+	ServerType        string `json:"server_type"` // eg. "monero-lws"
+	ServerVersion     string `json:"server_version"`
+	LastGitCommitHash string `json:"last_git_commit_hash"`
+	LastGitCommitDate string `json:"last_git_commit_date"`
+	GitBranchName     string `json:"git_branch_name"`
+	MoneroVersionFull string `json:"monero_version_full"`
+	API               uint32 `json:"api"`
+	MaxSubaddresses   uint32 `json:"max_subaddresses"`
+	Network           string `json:"network"` // eg. "main", "stage" or "test"
+	BlockchainHeight  uint64 `json:"blockchain_height"`
+}
+
+// GetVersion gets the version of the server's software.
+//
+// Servers that don't support it return ErrorUnsupported.
+func (c *Client) GetVersion() (*GetVersionResponse, error) {
+	return c.GetVersionContext(context.Background())
+}
+
+// GetVersionContext is like GetVersion but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (c *Client) GetVersionContext(ctx context.Context) (*GetVersionResponse, error) {
+	const path = EndpointGetVersion
+
+	var response = &GetVersionResponse{}
+
+	err := c.post(ctx, path, &struct{}{}, response, ErrorStandardRequestEncode)
+	if err != nil {
+		return &GetVersionResponse{}, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetVersion(t *testing.T) {
+	tryCount := 1 //Number of times to send HTTP Service Unavailable
+
+	response := GetVersionResponse{
+		ServerType:        "monero-lws",
+		ServerVersion:     "0.3_0",
+		LastGitCommitHash: "2a7e2f9",
+		LastGitCommitDate: "2024-06-01",
+		GitBranchName:     "master",
+		MoneroVersionFull: "0.18.3.3-release",
+		API:               1<<16 | 1,
+		MaxSubaddresses:   100,
+		Network:           "main",
+		BlockchainHeight:  3222370,
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != string(EndpointGetVersion) {
+			t.Error("GetVersion() called ", r.URL.Path)
+		}
+
+		if tryCount != 0 {
+			tryCount--
+
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		err := json.NewEncoder(w).Encode(response)
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:    "xmr_address",
+		client:     &http.Client{},
+		retryCount: tryCount,
+		retryTime:  time.Duration(0),
+		serverURL:  ts.URL,
+		viewKey:    "xmr_view_key",
+	}
+
+	resp, err := client.GetVersion()
+	if err != nil {
+		t.Fatal("GetVersion() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(*resp, response) {
+		t.Error("response struct didn't match the original data: ", resp)
+	}
+}
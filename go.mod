@@ -1,3 +1,7 @@
 module github.com/ChristianHering/Go-Monero-Light
 
-go 1.19
+go 1.21
+
+require golang.org/x/crypto v0.25.0
+
+require golang.org/x/sys v0.22.0 // indirect
@@ -4,13 +4,7 @@
 package gomonerolight
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"net/url"
-	"os"
-	"time"
+	"context"
 )
 
 // ImportRequestResponse returns the result of our account rescan,
@@ -20,69 +14,61 @@ import (
 // typically returned if the client needs to pay to complete the request.
 type ImportRequestResponse struct {
 	PaymentAddress   string `json:"payment_address"`
-	PaymentID        string `json:"payment_id"` // hex encoded binary
-	ImportFee        string `json:"import_fee"`
+	PaymentID        string `json:"payment_id" epee:"hex"` // hex encoded binary
+	ImportFee        string `json:"import_fee" epee:"uint64"`
 	NewRequest       bool   `json:"new_request"`
 	RequestFulfilled bool   `json:"request_fulfilled"`
 	Status           string `json:"status"`
 }
 
+// ImportRequestRequest holds the information needed for calling /import_request.
+//
+// FromHeight is optional, and only supported by newer servers. If it's
+// nil, our account is rescanned from Monero's genesis block.
+type ImportRequestRequest struct {
+	Address    string  `json:"address"`
+	ViewKey    string  `json:"view_key" epee:"hex"`   // hex encoded binary
+	FromHeight *uint64 `json:"from_height,omitempty"` // The block to rescan from (eg. our wallet's restore height)
+}
+
 // ImportRequest requests a rescan for our
 // account's address since Monero's genesis block.
 func (c *Client) ImportRequest() (*ImportRequestResponse, error) {
-	const path = "/import_request"
-
-	b := new(bytes.Buffer)
-
-	request := &StandardRequest{
-		Address: c.address,
-		ViewKey: c.viewKey,
-	}
-
-	err := json.NewEncoder(b).Encode(request)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to encode:\n\n%#v\n\nwith error:\n%v\n\n", *request, err)
-
-		return &ImportRequestResponse{}, ErrorStandardRequestEncode
-	}
-
-	url, err := url.JoinPath(c.serverURL, path)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to join:\n%s\nand\n%s\nwith error:\n\n%v\n\n", c.serverURL, path, err)
-
-		return &ImportRequestResponse{}, ErrorJoinPathFailed
-	}
-
-	retries := 0
-
-POST_REQUEST:
+	return c.ImportRequestContext(context.Background())
+}
 
-	resp, err := c.client.Post(url, "application/json", bytes.NewReader(b.Bytes()))
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to post:\n\n%s\n\n to our endpoint at:\n\n%s\n\nwith error:\n\n%v\n\n", b.String(), url, err)
+// ImportRequestContext is like ImportRequest but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (c *Client) ImportRequestContext(ctx context.Context) (*ImportRequestResponse, error) {
+	return c.importRequest(ctx, nil)
+}
 
-		return &ImportRequestResponse{}, ErrorPostRequestFailed
-	}
+// ImportRequestFrom requests a rescan for our account's
+// address since block 'fromHeight' (eg. our restore height).
+func (c *Client) ImportRequestFrom(fromHeight uint64) (*ImportRequestResponse, error) {
+	return c.ImportRequestFromContext(context.Background(), fromHeight)
+}
 
-	if resp.StatusCode == http.StatusServiceUnavailable {
-		if retries < c.retryCount {
-			time.Sleep(c.retryTime)
+// ImportRequestFromContext is like ImportRequestFrom but uses 'ctx'
+// to cancel the request or to stop waiting in between retries.
+func (c *Client) ImportRequestFromContext(ctx context.Context, fromHeight uint64) (*ImportRequestResponse, error) {
+	return c.importRequest(ctx, &fromHeight)
+}
 
-			goto POST_REQUEST
-		}
+func (c *Client) importRequest(ctx context.Context, fromHeight *uint64) (*ImportRequestResponse, error) {
+	const path = EndpointImportRequest
 
-		return &ImportRequestResponse{}, ErrorServiceUnavailable
-	} else if resp.StatusCode != http.StatusOK {
-		return &ImportRequestResponse{}, ErrorStatusCodeNotOK
+	request := &ImportRequestRequest{
+		Address:    c.address,
+		ViewKey:    c.viewKey,
+		FromHeight: fromHeight,
 	}
 
 	var response = &ImportRequestResponse{}
 
-	err = json.NewDecoder(resp.Body).Decode(response)
+	err := c.post(ctx, path, request, response, ErrorStandardRequestEncode)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to decode:\n\n%#v\n\nwith error:\n%v\n", response, err)
-
-		return &ImportRequestResponse{}, ErrorResponseUnmarshalFailed
+		return &ImportRequestResponse{}, err
 	}
 
 	return response, nil
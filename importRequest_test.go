@@ -89,3 +89,50 @@ func TestImportRequest(t *testing.T) {
 		t.Error("response struct didn't match the original data")
 	}
 }
+
+func TestImportRequestFrom(t *testing.T) {
+	fromHeight := uint64(3100000)
+
+	request := &ImportRequestRequest{
+		Address:    "xmr_address",
+		ViewKey:    "xmr_view_key",
+		FromHeight: &fromHeight,
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req = &ImportRequestRequest{}
+
+		err := json.NewDecoder(r.Body).Decode(req)
+		if err != nil {
+			t.Error("ImportRequestFrom() made an invalid request: ", err)
+		}
+
+		if !reflect.DeepEqual(req, request) {
+			t.Error("req struct didn't match the original data in request")
+		}
+
+		_, err = w.Write([]byte(`{"request_fulfilled":true,"status":"Approved"}`))
+		if err != nil {
+			t.Error("failed to write our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:   request.Address,
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   request.ViewKey,
+	}
+
+	resp, err := client.ImportRequestFrom(fromHeight)
+	if err != nil {
+		t.Fatal("ImportRequestFrom() returned the error: ", err)
+	}
+
+	if !resp.RequestFulfilled {
+		t.Error("response struct didn't match the original data: ", resp)
+	}
+}
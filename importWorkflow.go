@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// defaultImportPollInterval is how long an ImportWorkflow
+// waits in between polls if ImportOptions.PollInterval isn't set.
+const defaultImportPollInterval = 30 * time.Second
+
+var ErrorImportFeeInvalid = errors.New("server's import fee isn't a valid amount")
+
+// ImportState is the state of an ImportWorkflow.
+type ImportState int
+
+const (
+	ImportStateNone            ImportState = iota // We haven't requested a rescan yet
+	ImportStateAwaitingPayment                    // The server is waiting for its import fee to be paid, see PaymentInstruction
+	ImportStatePending                            // The rescan was requested (and paid for, if needed), but hasn't started yet
+	ImportStateFulfilled                          // The server is rescanning our account
+)
+
+func (s ImportState) String() string {
+	switch s {
+	case ImportStateNone:
+		return "none"
+	case ImportStateAwaitingPayment:
+		return "awaiting payment"
+	case ImportStatePending:
+		return "pending"
+	case ImportStateFulfilled:
+		return "fulfilled"
+	}
+
+	return "unknown"
+}
+
+// PaymentInstruction describes how to pay a server's import fee.
+type PaymentInstruction struct {
+	Address           string // The address to pay
+	PaymentID         string // hex encoded binary
+	Amount            Amount // The import fee
+	IntegratedAddress string // Address with PaymentID in it, if it could be built (eg. Address isn't a subaddress)
+	URI               string // A "monero:" URI for wallets, paying IntegratedAddress if there is one
+}
+
+// ImportUpdate is the state of an ImportWorkflow after a poll.
+type ImportUpdate struct {
+	State    ImportState
+	Status   string                 // The server's status message, if it sent one
+	Payment  *PaymentInstruction    // Set if State is ImportStateAwaitingPayment
+	Response *ImportRequestResponse // The server's response
+}
+
+// ImportOptions holds the settings for an ImportWorkflow.
+type ImportOptions struct {
+	FromHeight   uint64             // The block to rescan from (eg. our wallet's restore height). Defaults to Monero's genesis block.
+	PollInterval time.Duration      // How long Run waits in between polls. Defaults to 30 seconds.
+	OnUpdate     func(ImportUpdate) // Called after every poll that changes the State or Status
+}
+
+// ImportWorkflow walks through a (possibly paid) rescan of our account:
+// it requests the rescan, describes how to pay the server's import fee
+// if there is one, then polls /import_request until it's fulfilled.
+//
+// An ImportWorkflow isn't safe for concurrent use.
+type ImportWorkflow struct {
+	client *Client
+	opts   ImportOptions
+	last   ImportUpdate
+}
+
+// NewImportWorkflow creates an ImportWorkflow for our account using 'opts'.
+func (c *Client) NewImportWorkflow(opts ImportOptions) *ImportWorkflow {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultImportPollInterval
+	}
+
+	return &ImportWorkflow{client: c, opts: opts}
+}
+
+// State returns the workflow's state as of its last poll.
+func (w *ImportWorkflow) State() ImportState {
+	return w.last.State
+}
+
+// Poll calls /import_request once and returns the workflow's new state,
+// calling ImportOptions.OnUpdate if it changed.
+func (w *ImportWorkflow) Poll(ctx context.Context) (*ImportUpdate, error) {
+	var response *ImportRequestResponse
+	var err error
+
+	if w.opts.FromHeight > 0 {
+		response, err = w.client.ImportRequestFromContext(ctx, w.opts.FromHeight)
+	} else {
+		response, err = w.client.ImportRequestContext(ctx)
+	}
+
+	if err != nil {
+		return &ImportUpdate{}, err
+	}
+
+	update := ImportUpdate{
+		State:    ImportStatePending,
+		Status:   response.Status,
+		Response: response,
+	}
+
+	fee, err := response.ImportFeeAmount()
+	if err != nil {
+		return &ImportUpdate{}, ErrorImportFeeInvalid
+	}
+
+	switch {
+	case response.RequestFulfilled:
+		update.State = ImportStateFulfilled
+	case fee > 0:
+		update.State = ImportStateAwaitingPayment
+		update.Payment = newPaymentInstruction(response.PaymentAddress, response.PaymentID, fee)
+	}
+
+	changed := update.State != w.last.State || update.Status != w.last.Status
+
+	w.last = update
+
+	if changed && w.opts.OnUpdate != nil {
+		w.opts.OnUpdate(update)
+	}
+
+	return &update, nil
+}
+
+// Run polls /import_request every ImportOptions.PollInterval until the
+// rescan is fulfilled, returning early with ctx.Err() if 'ctx' is done.
+//
+// Pay the import fee (see ImportUpdate.Payment) from ImportOptions.OnUpdate
+// or another goroutine, or Run will wait until 'ctx' is done.
+func (w *ImportWorkflow) Run(ctx context.Context) (*ImportUpdate, error) {
+	for {
+		update, err := w.Poll(ctx)
+		if err != nil {
+			return update, err
+		}
+
+		if update.State == ImportStateFulfilled {
+			return update, nil
+		}
+
+		err = sleep(ctx, w.opts.PollInterval)
+		if err != nil {
+			return update, err
+		}
+	}
+}
+
+// newPaymentInstruction describes how to pay 'amount' to 'address' with 'paymentID'
+func newPaymentInstruction(address string, paymentID string, amount Amount) *PaymentInstruction {
+	p := &PaymentInstruction{
+		Address:   address,
+		PaymentID: paymentID,
+		Amount:    amount,
+	}
+
+	query := url.Values{}
+	query.Set("tx_amount", amount.String())
+
+	// Long (or missing) payment IDs can't be integrated, so they're sent on their own
+	integrated, err := IntegratedAddress(address, paymentID)
+	if err == nil {
+		p.IntegratedAddress = integrated
+	} else if paymentID != "" {
+		query.Set("tx_payment_id", paymentID)
+	}
+
+	to := address
+	if p.IntegratedAddress != "" {
+		to = p.IntegratedAddress
+	}
+
+	p.URI = "monero:" + to + "?" + query.Encode()
+
+	return p
+}
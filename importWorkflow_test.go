@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImportWorkflow(t *testing.T) {
+	polls := 0
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req = &ImportRequestRequest{}
+
+		err := json.NewDecoder(r.Body).Decode(req)
+		if err != nil {
+			t.Error("the workflow made an invalid request: ", err)
+		}
+
+		if req.FromHeight == nil || *req.FromHeight != 3100000 {
+			t.Error("the workflow requested a rescan from ", req.FromHeight)
+		}
+
+		polls++
+
+		var response ImportRequestResponse
+
+		switch {
+		case polls <= 2: // The fee is paid after the second poll
+			response = ImportRequestResponse{
+				PaymentAddress: testAddress,
+				PaymentID:      "420fa29b2d9a49f5",
+				ImportFee:      "50000000000",
+				NewRequest:     polls == 1,
+				Status:         "Payment required",
+			}
+		case polls == 3:
+			response = ImportRequestResponse{Status: "Payment received"}
+		default:
+			response = ImportRequestResponse{RequestFulfilled: true, Status: "Approved"}
+		}
+
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	var updates []ImportUpdate
+
+	workflow := client.NewImportWorkflow(ImportOptions{
+		FromHeight:   3100000,
+		PollInterval: time.Millisecond,
+		OnUpdate:     func(u ImportUpdate) { updates = append(updates, u) },
+	})
+
+	if workflow.State() != ImportStateNone {
+		t.Error("a new workflow's state was ", workflow.State())
+	}
+
+	update, err := workflow.Run(context.Background())
+	if err != nil {
+		t.Fatal("Run() returned the error: ", err)
+	}
+
+	if update.State != ImportStateFulfilled || workflow.State() != ImportStateFulfilled || polls != 4 {
+		t.Errorf("Run() returned the state %v after %d polls", update.State, polls)
+	}
+
+	var states []ImportState
+	for _, u := range updates {
+		states = append(states, u.State)
+	}
+
+	// The second poll didn't change anything
+	if !reflect.DeepEqual(states, []ImportState{ImportStateAwaitingPayment, ImportStatePending, ImportStateFulfilled}) {
+		t.Error("OnUpdate() was called with the states: ", states)
+	}
+
+	payment := updates[0].Payment
+	if payment == nil {
+		t.Fatal("the first update didn't have a payment instruction")
+	}
+
+	expected := &PaymentInstruction{
+		Address:           testAddress,
+		PaymentID:         "420fa29b2d9a49f5",
+		Amount:            50000000000,
+		IntegratedAddress: payment.IntegratedAddress,
+		URI:               "monero:" + payment.IntegratedAddress + "?tx_amount=0.05",
+	}
+
+	if payment.IntegratedAddress == "" || !reflect.DeepEqual(payment, expected) {
+		t.Errorf("the payment instruction was: %+v", payment)
+	}
+}
+
+func TestImportWorkflowLongPaymentID(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"payment_address":"` + testAddress + `","payment_id":"e8021307f3e7f10a41a712ea7f26d4f9f72f78597011cc5859b11d3eaa97d998","import_fee":"1500000000000"}`))
+		if err != nil {
+			t.Error("failed to write our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	update, err := client.NewImportWorkflow(ImportOptions{}).Poll(context.Background())
+	if err != nil {
+		t.Fatal("Poll() returned the error: ", err)
+	}
+
+	expected := "monero:" + testAddress + "?tx_amount=1.5&tx_payment_id=e8021307f3e7f10a41a712ea7f26d4f9f72f78597011cc5859b11d3eaa97d998"
+
+	if update.State != ImportStateAwaitingPayment || update.Payment.IntegratedAddress != "" || update.Payment.URI != expected {
+		t.Errorf("Poll() returned: %+v %+v", update, update.Payment)
+	}
+}
+
+func TestImportWorkflowBadFee(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"payment_address":"payment_addr","import_fee":"a lot"}`))
+		if err != nil {
+			t.Error("failed to write our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	_, err := client.NewImportWorkflow(ImportOptions{}).Poll(context.Background())
+	if err != ErrorImportFeeInvalid {
+		t.Error("Poll() returned the error: ", err)
+	}
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"net/http"
+)
+
+// Call is a single call to one of our server's endpoints, as
+// seen by an Interceptor. Requests sent for the call (including
+// retries) are sent with its Header.
+type Call struct {
+	Endpoint   Endpoint    // The endpoint being called (eg. "/get_address_txs")
+	Address    string      // The XMR address of the client making the call
+	Request    interface{} // The request struct (eg. *LoginRequest), encoded after every interceptor has run
+	Response   interface{} // The response struct (eg. *LoginResponse), decoded into when the call succeeds
+	Header     http.Header // Extra headers to send (eg. for authentication or tracing)
+	Attempts   int         // The number of requests sent for the call so far
+	ServerURL  string      // The server the last request was sent to
+	StatusCode int         // The HTTP status code of the last response, if there was one
+
+	encodeErr    error                   // Returned if Request couldn't be encoded
+	server       string                  // If set, requests are only sent to this server instead of one from our pool
+	transactions func(Transaction) error // If set, a /get_address_txs response's transactions are streamed to it instead of being kept in Response
+	binary       bool                    // If set, Request is sent in binary (epee) even if our client's Encoding isn't EncodingBinary
+	mediaType    string                  // The media type of the last response (eg. "application/json"), if there was one
+	noRetry      bool                    // If set, failed requests aren't retried, whatever our client's RetryPolicy says
+}
+
+// Invoker makes a call, returning an error if it failed.
+type Invoker func(ctx context.Context, call *Call) error
+
+// Interceptor runs around every call our client makes. It can
+// change the call (eg. by adding headers) before calling 'next'
+// to make it, then look at the call's response and error.
+//
+// Interceptors run in the order they're given in Config.Interceptors,
+// with the first one running outermost.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) error
+
+// chain returns an Invoker that runs 'interceptors' around 'invoker'.
+func chain(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+
+		invoker = func(ctx context.Context, call *Call) error {
+			return interceptor(ctx, call, next)
+		}
+	}
+
+	return invoker
+}
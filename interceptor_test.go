@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	tryCount := 1 //Number of times to send HTTP Service Unavailable
+
+	response := LoginResponse{NewAddress: true, StartHeight: 3223243}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Error("the interceptor's header wasn't sent, got: ", r.Header)
+		}
+
+		if tryCount != 0 {
+			tryCount--
+
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		err := json.NewEncoder(w).Encode(response)
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	var order []string
+
+	outer := func(ctx context.Context, call *Call, next Invoker) error {
+		order = append(order, "outer")
+
+		call.Header.Set("Authorization", "Bearer token")
+
+		err := next(ctx, call)
+
+		order = append(order, "outer done")
+
+		return err
+	}
+
+	inner := func(ctx context.Context, call *Call, next Invoker) error {
+		order = append(order, "inner")
+
+		if call.Endpoint != EndpointLogin || call.Address != "xmr_address" {
+			t.Errorf("the interceptor saw the call %+v", call)
+		}
+
+		if req, ok := call.Request.(*LoginRequest); !ok || !req.CreateAccount {
+			t.Errorf("the interceptor saw the request %#v", call.Request)
+		}
+
+		err := next(ctx, call)
+		if err != nil {
+			t.Error("the interceptor saw the error: ", err)
+		}
+
+		if call.Attempts != 2 || call.StatusCode != http.StatusOK || call.ServerURL != ts.URL {
+			t.Errorf("the interceptor saw the call %+v", call)
+		}
+
+		if !reflect.DeepEqual(call.Response, &response) {
+			t.Errorf("the interceptor saw the response %#v", call.Response)
+		}
+
+		order = append(order, "inner done")
+
+		return err
+	}
+
+	client, err := NewClient(Config{
+		Address:      "xmr_address",
+		Interceptors: []Interceptor{outer, inner},
+		RetryCount:   tryCount,
+		ServerURL:    ts.URL,
+		ViewKey:      "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	_, err = client.Login(&LoginRequest{CreateAccount: true})
+	if err != nil {
+		t.Error("Login() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(order, []string{"outer", "inner", "inner done", "outer done"}) {
+		t.Error("interceptors ran in the order: ", order)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	errBlocked := errors.New("blocked")
+
+	client, err := NewClient(Config{
+		Address: "xmr_address",
+		Interceptors: []Interceptor{func(ctx context.Context, call *Call, next Invoker) error {
+			return errBlocked
+		}},
+		ServerURL: "http://127.0.0.1:0",
+		ViewKey:   "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	_, err = client.GetAddressTxs()
+	if !errors.Is(err, errBlocked) {
+		t.Error("GetAddressTxs() returned the error: ", err)
+	}
+}
@@ -0,0 +1,297 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+// IterAddressTxs is like GetAddressTxsContext, but calls 'fn' with each
+// transaction as it's decoded instead of keeping them all in memory, so
+// accounts with huge histories can be read one transaction at a time.
+//
+// The returned response holds every field but Transactions. If 'fn'
+// returns an error, iteration stops and IterAddressTxs returns it.
+//
+// Transactions can only be streamed from JSON responses. Binary (epee)
+// responses are decoded whole before 'fn' is called, so they're limited
+// in size like other responses. JSON responses aren't limited in size
+// unless Config.MaxResponseSizes has EndpointGetAddressTxs, but each
+// transaction in them is limited to 1 MiB.
+func (c *Client) IterAddressTxs(ctx context.Context, fn func(Transaction) error) (*GetAddressTxsResponse, error) {
+	const path = EndpointGetAddressTxs
+
+	var response = &GetAddressTxsResponse{}
+
+	err := c.do(ctx, &Call{
+		Endpoint: path,
+		Request: &StandardRequest{
+			Address: c.address,
+			ViewKey: c.viewKey,
+		},
+		Response:     response,
+		encodeErr:    ErrorStandardRequestEncode,
+		transactions: fn,
+	})
+
+	err = c.trackAccount(path, err)
+	if err != nil {
+		return &GetAddressTxsResponse{}, err
+	}
+
+	return response, nil
+}
+
+// streamAddressTxs decodes a /get_address_txs response into cl.Response,
+// passing each transaction to cl.transactions instead of keeping them.
+func (c *Client) streamAddressTxs(resp *http.Response, cl *Call, log *slog.Logger) error {
+	response := cl.Response.(*GetAddressTxsResponse)
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	// Binary responses are decoded whole, so they're always limited
+	limit, limited := c.maxResponseSizes[cl.Endpoint]
+	if mediaType == contentTypeEpee {
+		limit, limited = c.maxResponseSize(cl.Endpoint), true
+	}
+
+	var r io.Reader = resp.Body
+	if limited {
+		r = io.LimitReader(r, limit+1)
+	}
+
+	counter := &countingReader{r: r}
+
+	var err error
+	if mediaType == contentTypeEpee {
+		err = streamEpeeAddressTxs(counter, response, cl.transactions, c.strictDecoding)
+	} else {
+		err = streamJSONAddressTxs(counter, response, cl.transactions, c.strictDecoding)
+	}
+
+	if limited && counter.n > limit || err == errValueTooLarge {
+		err = &DecodeError{Endpoint: cl.Endpoint, Err: ErrorResponseTooLarge}
+	}
+
+	if err != nil {
+		if stop, ok := err.(*stopError); ok {
+			return stop.err
+		}
+
+		decodeErr, ok := err.(*DecodeError)
+		if !ok {
+			decodeErr = newDecodeError(cl.Endpoint, err)
+		}
+
+		log.Error("failed to decode response", slog.String("field", decodeErr.Field), slog.Any("error", decodeErr))
+
+		return decodeErr
+	}
+
+	return nil
+}
+
+// maxStreamedValue is the largest value (eg. a transaction) we decode
+// from a streamed JSON response, in bytes. Unlike the response, which
+// is only limited by Config.MaxResponseSizes, values are kept in memory.
+const maxStreamedValue = defaultMaxResponseSize
+
+// errValueTooLarge is returned when a value in a streamed response is larger than maxStreamedValue
+var errValueTooLarge = errors.New("json: value in streamed response is too large")
+
+// errUnexpectedJSON is returned when a streamed response isn't shaped like we expect
+var errUnexpectedJSON = errors.New("json: unexpected token in response")
+
+// stopError holds an error returned by a caller's callback, which stops decoding
+type stopError struct {
+	err error
+}
+
+func (e *stopError) Error() string {
+	return e.err.Error()
+}
+
+// streamJSONAddressTxs decodes the JSON object in 'r' into 'response'
+// token by token, passing each transaction to 'fn' as it's decoded.
+func streamJSONAddressTxs(r io.Reader, response *GetAddressTxsResponse, fn func(Transaction) error, strict bool) error {
+	budget := &valueLimitReader{r: r, n: maxStreamedValue}
+
+	dec := json.NewDecoder(budget)
+
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+
+	err := expectDelim(dec, '{')
+	if err != nil {
+		return err
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key, _ := token.(string)
+
+		if key != "transactions" {
+			// Header fields are small, so decode them on their own
+			var value json.RawMessage
+
+			err = dec.Decode(&value)
+			if err != nil {
+				return err
+			}
+
+			budget.reset()
+
+			b, err := json.Marshal(map[string]json.RawMessage{key: value})
+			if err != nil {
+				return err
+			}
+
+			err = unmarshalJSON(b, response, strict)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		token, err = dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if token == nil { // "transactions": null
+			continue
+		}
+
+		if token != json.Delim('[') {
+			return &json.UnmarshalTypeError{Value: "value", Type: reflect.TypeOf([]Transaction(nil)), Field: "transactions"}
+		}
+
+		for dec.More() {
+			var tx Transaction
+
+			err = dec.Decode(&tx)
+			if err != nil {
+				return err
+			}
+
+			budget.reset()
+
+			err = fn(tx)
+			if err != nil {
+				return &stopError{err: err}
+			}
+		}
+
+		err = expectDelim(dec, ']')
+		if err != nil {
+			return err
+		}
+	}
+
+	err = expectDelim(dec, '}')
+	if err != nil {
+		return err
+	}
+
+	_, err = dec.Token()
+	if err != io.EOF {
+		return errTrailingJSON
+	}
+
+	return nil
+}
+
+// streamEpeeAddressTxs decodes the epee portable storage in 'r' into
+// 'response', then passes each transaction to 'fn'.
+func streamEpeeAddressTxs(r io.Reader, response *GetAddressTxsResponse, fn func(Transaction) error, strict bool) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	err = unmarshalEpee(b, response, strict)
+	if err != nil {
+		return err
+	}
+
+	txs := response.Transactions
+	response.Transactions = nil
+
+	for _, tx := range txs {
+		err = fn(tx)
+		if err != nil {
+			return &stopError{err: err}
+		}
+	}
+
+	return nil
+}
+
+// expectDelim reads the next token from 'dec', which must be 'delim'.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return errUnexpectedJSON
+	}
+
+	return nil
+}
+
+// valueLimitReader stops reading from r once maxStreamedValue bytes have
+// been read since its last reset. A json.Decoder only reads more while it
+// needs it to finish a value, so resetting it after each value limits
+// how large the next one can be.
+type valueLimitReader struct {
+	r io.Reader
+	n int64 // Bytes left until we stop reading
+}
+
+func (l *valueLimitReader) reset() {
+	l.n = maxStreamedValue
+}
+
+func (l *valueLimitReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, errValueTooLarge
+	}
+
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	return n, err
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestIterAddressTxs(t *testing.T) {
+	response := GetAddressTxsResponse{
+		TotalReceived:    "31415926535897",
+		ScannedHeight:    3222370,
+		StartHeight:      3000000,
+		BlockchainHeight: 3222371,
+	}
+
+	for i := 0; i < 1000; i++ {
+		response.Transactions = append(response.Transactions, Transaction{ID: uint64(i), Hash: strconv.Itoa(i)})
+	}
+
+	trailing := ""
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(response)
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+
+		_, _ = w.Write([]byte(trailing))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		Address:   "xmr_address",
+		ServerURL: ts.URL,
+		ViewKey:   "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	var ids []uint64
+
+	header, err := client.IterAddressTxs(context.Background(), func(tx Transaction) error {
+		ids = append(ids, tx.ID)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal("IterAddressTxs() returned the error: ", err)
+	}
+
+	if header.TotalReceived != response.TotalReceived || header.ScannedHeight != 3222370 || header.StartHeight != 3000000 || header.BlockchainHeight != 3222371 {
+		t.Error("IterAddressTxs() returned the header ", header)
+	}
+
+	if header.Transactions != nil {
+		t.Error("IterAddressTxs() kept the transactions it streamed")
+	}
+
+	for i, id := range ids {
+		if id != uint64(i) {
+			t.Fatalf("transaction %d was streamed out of order", id)
+		}
+	}
+
+	if len(ids) != len(response.Transactions) {
+		t.Fatal("this many transactions were streamed: ", len(ids))
+	}
+
+	// Stopping early returns our error, and isn't blamed on the server
+	stop := errors.New("stop")
+
+	_, err = client.IterAddressTxs(context.Background(), func(tx Transaction) error {
+		if tx.ID == 10 {
+			return stop
+		}
+
+		return nil
+	})
+	if err != stop {
+		t.Error("IterAddressTxs() didn't return our callback's error: ", err)
+	}
+
+	if client.Servers()[0].ErrorRate != 0 {
+		t.Error("stopping early was counted as a server error")
+	}
+
+	trailing = "garbage"
+
+	_, err = client.IterAddressTxs(context.Background(), func(tx Transaction) error { return nil })
+	if !errors.Is(err, ErrorResponseTrailingData) {
+		t.Error("IterAddressTxs() returned the error: ", err)
+	}
+}
+
+func TestIterAddressTxsLimits(t *testing.T) {
+	response := GetAddressTxsResponse{}
+
+	// The response is larger than the largest transaction we decode, but its transactions aren't
+	for i := 0; i < 3000; i++ {
+		response.Transactions = append(response.Transactions, Transaction{ID: uint64(i), Hash: strings.Repeat("ab", 512)})
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(response)
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		Address:   "xmr_address",
+		ServerURL: ts.URL,
+		ViewKey:   "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	n := 0
+
+	_, err = client.IterAddressTxs(context.Background(), func(tx Transaction) error {
+		n++
+
+		return nil
+	})
+	if err != nil || n != len(response.Transactions) {
+		t.Fatalf("IterAddressTxs() streamed %d transactions and returned the error: %v", n, err)
+	}
+
+	response.Transactions = append(response.Transactions, Transaction{Hash: strings.Repeat("ab", maxStreamedValue)})
+
+	_, err = client.IterAddressTxs(context.Background(), func(tx Transaction) error { return nil })
+	if !errors.Is(err, ErrorResponseTooLarge) {
+		t.Error("IterAddressTxs() returned the error: ", err)
+	}
+}
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// redacted replaces the value of any logged attribute holding a secret
+const redacted = "[REDACTED]"
+
+// secretKeys lists the (normalized) attribute keys we never log
+// the values of. See normalizeKey() for how keys are normalized.
+var secretKeys = map[string]bool{
+	"viewkey":         true,
+	"privateviewkey":  true,
+	"spendkey":        true,
+	"privatespendkey": true,
+	"key":             true,
+	"tx":              true,
+	"rawtx":           true,
+	"auth":            true,
+	"token":           true,
+}
+
+// normalizeKey lowercases 'key' and strips '_' and '-'
+// so "view_key", "viewKey" and "ViewKey" are all the same.
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// newLogger returns a logger that logs to 'logger' with any secrets
+// (view keys, spend keys, raw transactions, etc.) redacted. If 'logger'
+// is nil, the returned logger discards everything.
+func newLogger(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.New(discardHandler{})
+	}
+
+	if _, ok := logger.Handler().(*redactHandler); ok {
+		return logger
+	}
+
+	return slog.New(&redactHandler{handler: logger.Handler()})
+}
+
+// log returns our client's logger, which is silent by default.
+func (c *Client) log() *slog.Logger {
+	if c.logger == nil {
+		return slog.New(discardHandler{})
+	}
+
+	return c.logger
+}
+
+// redactHandler wraps a slog.Handler, redacting
+// the values of attributes whose keys hold secrets.
+type redactHandler struct {
+	handler slog.Handler
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+
+	r.Attrs(func(a slog.Attr) bool {
+		record.AddAttrs(redactAttr(a))
+
+		return true
+	})
+
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redactedAttrs[i] = redactAttr(a)
+	}
+
+	return &redactHandler{handler: h.handler.WithAttrs(redactedAttrs)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{handler: h.handler.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	if secretKeys[normalizeKey(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = redactAttr(ga)
+		}
+
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	}
+
+	return a
+}
+
+// jsonAttr converts the JSON document 'b' into a (possibly nested)
+// group attribute so a redactHandler can redact the secrets in it.
+func jsonAttr(key string, b []byte) slog.Attr {
+	var v interface{}
+
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return slog.String(key, redacted) // We can't tell what's secret in invalid JSON
+	}
+
+	return slog.Attr{Key: key, Value: jsonValue(v)}
+}
+
+func jsonValue(v interface{}) slog.Value {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		attrs := make([]slog.Attr, len(keys))
+		for i, k := range keys {
+			attrs[i] = slog.Attr{Key: k, Value: jsonValue(v[k])}
+		}
+
+		return slog.GroupValue(attrs...)
+	case []interface{}:
+		attrs := make([]slog.Attr, len(v))
+		for i, e := range v {
+			attrs[i] = slog.Attr{Key: strconv.Itoa(i), Value: jsonValue(e)}
+		}
+
+		return slog.GroupValue(attrs...)
+	default:
+		return slog.AnyValue(v)
+	}
+}
+
+// discardHandler is a slog.Handler that drops every record.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
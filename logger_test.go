@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoggerRedactsSecrets(t *testing.T) {
+	const viewKey = "f359631075708155cc3d92a32b75a7d02a5dcf27756707b47a2b31b21c389501"
+	const tx = "30e4f78c6706e8720396557b4a961e80c1c7d2f6e3951969763bcde7c0bfa3b8"
+
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close() // Make every request fail so our request bodies get logged
+
+	buf := new(bytes.Buffer)
+
+	client, err := NewClient(Config{
+		Address:   "xmr_address",
+		Logger:    slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		ServerURL: ts.URL,
+		ViewKey:   viewKey,
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	_, err = client.Login(&LoginRequest{CreateAccount: true})
+	if err == nil {
+		t.Error("Login() didn't return an error")
+	}
+
+	_, err = client.SubmitRawTx(&SubmitRawTxRequest{Tx: tx})
+	if err == nil {
+		t.Error("SubmitRawTx() didn't return an error")
+	}
+
+	client.log().Info("custom", slog.Group("keys", slog.String("spendKey", "secret_spend_key"), slog.String("token", "secret_webhook_token")))
+
+	logs := buf.String()
+
+	if !strings.Contains(logs, "xmr_address") || !strings.Contains(logs, redacted) {
+		t.Error("requests weren't logged with secrets redacted:\n", logs)
+	}
+
+	for _, secret := range []string{viewKey, tx, "secret_spend_key", "secret_webhook_token"} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("the secret %q was logged:\n%s", secret, logs)
+		}
+	}
+}
+
+func TestLoggerSilentByDefault(t *testing.T) {
+	logger := newLogger(nil)
+
+	if logger.Enabled(context.Background(), slog.LevelError) {
+		t.Error("the default logger isn't silent")
+	}
+}
@@ -4,14 +4,8 @@
 package gomonerolight
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
-	"fmt"
-	"net/http"
-	"net/url"
-	"os"
-	"time"
 )
 
 // LoginRequest holds the information needed for calling /login.
@@ -22,7 +16,7 @@ import (
 // CreateAccount and GeneratedLocally define
 type LoginRequest struct {
 	Address          string `json:"address"`
-	ViewKey          string `json:"view_key"` // hex encoded binary
+	ViewKey          string `json:"view_key" epee:"hex"` // hex encoded binary
 	CreateAccount    bool   `json:"create_account"`
 	GeneratedLocally bool   `json:"generated_locally"`
 }
@@ -31,7 +25,8 @@ type LoginRequest struct {
 //
 // NewAddress lets you know if you're a new address to the server
 // and if so, you *may* receive HTTP 403 (Forbidden) status codes
-// until your account is manually reviewed.
+// (returned as ErrorAccountPendingApproval) until your account is
+// manually reviewed. See Client.WaitForActivation().
 //
 // GeneratedLocally and StartHeight are optional.
 type LoginResponse struct {
@@ -50,57 +45,28 @@ var ErrorLoginRequestEncode = errors.New("failed to encode login request using d
 // They will be overwritten with the values set
 // for your client 'c'.
 func (c *Client) Login(request *LoginRequest) (*LoginResponse, error) {
-	const path = "/login"
+	return c.LoginContext(context.Background(), request)
+}
 
-	b := new(bytes.Buffer)
+// LoginContext is like Login but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (c *Client) LoginContext(ctx context.Context, request *LoginRequest) (*LoginResponse, error) {
+	const path = EndpointLogin
 
 	request.Address = c.address
 	request.ViewKey = c.viewKey
 
-	err := json.NewEncoder(b).Encode(request)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to encode:\n\n%#v\n\nwith error:\n%v\n\n", *request, err)
-
-		return &LoginResponse{}, ErrorLoginRequestEncode
-	}
-
-	url, err := url.JoinPath(c.serverURL, path)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to join:\n%s\nand\n%s\nwith error:\n\n%v\n\n", c.serverURL, path, err)
-
-		return &LoginResponse{}, ErrorJoinPathFailed
-	}
-
-	retries := 0
-
-POST_REQUEST:
+	last := *request
+	c.lastLogin.Store(&last)
 
-	resp, err := c.client.Post(url, "application/json", bytes.NewReader(b.Bytes()))
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to post:\n\n%s\n\n to our endpoint at:\n\n%s\n\nwith error:\n\n%v\n\n", b.String(), url, err)
-
-		return &LoginResponse{}, ErrorPostRequestFailed
-	}
-
-	if resp.StatusCode == http.StatusServiceUnavailable {
-		if retries < c.retryCount {
-			time.Sleep(c.retryTime)
-
-			goto POST_REQUEST
-		}
+	var response = &LoginResponse{}
 
-		return &LoginResponse{}, ErrorServiceUnavailable
-	} else if resp.StatusCode != http.StatusOK {
-		return &LoginResponse{}, ErrorStatusCodeNotOK
-	}
+	err := c.post(ctx, path, request, response, ErrorLoginRequestEncode)
 
-	var response = &LoginResponse{}
+	c.trackLogin(&last, response, err)
 
-	err = json.NewDecoder(resp.Body).Decode(response)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to decode:\n\n%#v\n\nwith error:\n%v\n", response, err)
-
-		return &LoginResponse{}, ErrorResponseUnmarshalFailed
+		return &LoginResponse{}, err
 	}
 
 	return response, nil
@@ -0,0 +1,22 @@
+module github.com/ChristianHering/Go-Monero-Light/lwsotel
+
+go 1.21
+
+require (
+	github.com/ChristianHering/Go-Monero-Light v0.0.0
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/otel/metric v1.28.0 // indirect
+	golang.org/x/crypto v0.25.0 // indirect
+	golang.org/x/sys v0.22.0 // indirect
+)
+
+// Build against the client in this repository during development
+replace github.com/ChristianHering/Go-Monero-Light => ../
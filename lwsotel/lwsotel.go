@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+// Package lwsotel creates OpenTelemetry spans for
+// calls made by gomonerolight clients. It's a module of
+// its own, so the client doesn't depend on OpenTelemetry.
+//
+// Pass its Interceptor to every client you want traced:
+//
+//	client, err := gomonerolight.NewClient(gomonerolight.Config{
+//		Interceptors: []gomonerolight.Interceptor{lwsotel.Interceptor(lwsotel.Options{})},
+//		...
+//	})
+//
+// Spans never hold secrets like view keys, nor XMR addresses.
+package lwsotel
+
+import (
+	"context"
+
+	gomonerolight "github.com/ChristianHering/Go-Monero-Light"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ChristianHering/Go-Monero-Light/lwsotel"
+
+// Options holds the settings for our Interceptor.
+type Options struct {
+	TracerProvider trace.TracerProvider          // Defaults to otel.GetTracerProvider()
+	Propagator     propagation.TextMapPropagator // Injects trace context into requests. Defaults to otel.GetTextMapPropagator().
+}
+
+// Interceptor returns an interceptor that creates a span for every
+// call made by a client, and propagates its trace context to the
+// server. Pass it in Config.Interceptors.
+func Interceptor(opts Options) gomonerolight.Interceptor {
+	return func(ctx context.Context, call *gomonerolight.Call, next gomonerolight.Invoker) error {
+		provider := opts.TracerProvider
+		if provider == nil {
+			provider = otel.GetTracerProvider()
+		}
+
+		propagator := opts.Propagator
+		if propagator == nil {
+			propagator = otel.GetTextMapPropagator()
+		}
+
+		ctx, span := provider.Tracer(tracerName).Start(ctx, string(call.Endpoint),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("lws.endpoint", string(call.Endpoint))),
+		)
+		defer span.End()
+
+		propagator.Inject(ctx, propagation.HeaderCarrier(call.Header))
+
+		err := next(ctx, call)
+
+		span.SetAttributes(
+			attribute.String("server.url", call.ServerURL),
+			attribute.Int("lws.attempts", call.Attempts),
+		)
+
+		if call.StatusCode != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", call.StatusCode))
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return err
+		}
+
+		span.SetAttributes(responseAttributes(call.Response)...)
+
+		return nil
+	}
+}
+
+// responseAttributes returns the heights and counts in 'response'
+func responseAttributes(response interface{}) []attribute.KeyValue {
+	switch r := response.(type) {
+	case *gomonerolight.LoginResponse:
+		return []attribute.KeyValue{
+			attribute.Bool("lws.new_address", r.NewAddress),
+			attribute.Int64("lws.start_height", int64(r.StartHeight)),
+		}
+	case *gomonerolight.GetAddressInfoResponse:
+		return []attribute.KeyValue{
+			attribute.Int64("lws.scanned_height", int64(r.ScannedHeight)),
+			attribute.Int64("lws.scanned_block_height", int64(r.ScannedBlockHeight)),
+			attribute.Int64("lws.blockchain_height", int64(r.BlockchainHeight)),
+			attribute.Int("lws.spent_output_count", len(r.SpentOutputs)),
+		}
+	case *gomonerolight.GetAddressTxsResponse:
+		return []attribute.KeyValue{
+			attribute.Int64("lws.scanned_height", int64(r.ScannedHeight)),
+			attribute.Int64("lws.scanned_block_height", int64(r.ScannedBlockHeight)),
+			attribute.Int64("lws.blockchain_height", int64(r.BlockchainHeight)),
+			attribute.Int("lws.transaction_count", len(r.Transactions)),
+		}
+	case *gomonerolight.GetUnspentOutsResponse:
+		return []attribute.KeyValue{
+			attribute.Int("lws.output_count", len(r.Outputs)),
+		}
+	case *gomonerolight.GetRandomOutsResponse:
+		return []attribute.KeyValue{
+			attribute.Int("lws.amount_count", len(r.AmountOuts)),
+		}
+	case *gomonerolight.ImportRequestResponse:
+		return []attribute.KeyValue{
+			attribute.Bool("lws.new_request", r.NewRequest),
+			attribute.Bool("lws.request_fulfilled", r.RequestFulfilled),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package lwsotel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gomonerolight "github.com/ChristianHering/Go-Monero-Light"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInterceptor(t *testing.T) {
+	const viewKey = "f359631075708155cc3d92a32b75a7d02a5dcf27756707b47a2b31b21c389501"
+
+	var traceparent string
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/get_address_txs" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		traceparent = r.Header.Get("Traceparent")
+
+		err := json.NewEncoder(w).Encode(gomonerolight.GetAddressTxsResponse{
+			ScannedHeight:    3222370,
+			BlockchainHeight: 3222371,
+			Transactions:     make([]gomonerolight.Transaction, 3),
+		})
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+
+	client, err := gomonerolight.NewClient(gomonerolight.Config{
+		Address: "xmr_address",
+		Interceptors: []gomonerolight.Interceptor{Interceptor(Options{
+			TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+			Propagator:     propagation.TraceContext{},
+		})},
+		ServerURL: ts.URL,
+		ViewKey:   viewKey,
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	_, err = client.GetAddressTxs()
+	if err != nil {
+		t.Fatal("GetAddressTxs() returned the error: ", err)
+	}
+
+	_, _ = client.Login(&gomonerolight.LoginRequest{})
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatal("this many spans were recorded: ", len(spans))
+	}
+
+	txs := spans[0]
+
+	if txs.Name() != "/get_address_txs" || !strings.Contains(traceparent, txs.SpanContext().TraceID().String()) {
+		t.Errorf("the span %q wasn't propagated, the server got the traceparent %q", txs.Name(), traceparent)
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, a := range txs.Attributes() {
+		attrs[a.Key] = a.Value
+
+		if strings.Contains(a.Value.Emit(), viewKey) || strings.Contains(a.Value.Emit(), "xmr_address") {
+			t.Errorf("the span's attribute %s held a secret", a.Key)
+		}
+	}
+
+	if attrs["lws.transaction_count"].AsInt64() != 3 || attrs["lws.blockchain_height"].AsInt64() != 3222371 {
+		t.Error("the span's attributes were ", txs.Attributes())
+	}
+
+	if attrs["server.url"].AsString() != ts.URL || attrs["lws.attempts"].AsInt64() != 1 {
+		t.Error("the span's attributes were ", txs.Attributes())
+	}
+
+	if spans[1].Status().Code != codes.Error {
+		t.Error("the failed call's span didn't have an error status: ", spans[1].Status())
+	}
+}
@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+// Package lwsprom records Prometheus metrics for
+// calls made by gomonerolight clients. It's a module of
+// its own, so the client doesn't depend on Prometheus.
+//
+// Create a Collector, register it and pass its Interceptor()
+// to every client you want metrics for:
+//
+//	collector := lwsprom.NewCollector(lwsprom.Options{})
+//	err := collector.Register(prometheus.DefaultRegisterer)
+//	...
+//	client, err := gomonerolight.NewClient(gomonerolight.Config{
+//		Interceptors: []gomonerolight.Interceptor{collector.Interceptor()},
+//		...
+//	})
+package lwsprom
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	gomonerolight "github.com/ChristianHering/Go-Monero-Light"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// endpoints lists the endpoints a Collector's metrics are initialized for
+var endpoints = []gomonerolight.Endpoint{
+	gomonerolight.EndpointLogin,
+	gomonerolight.EndpointGetAddressInfo,
+	gomonerolight.EndpointGetAddressTxs,
+	gomonerolight.EndpointGetUnspentOuts,
+	gomonerolight.EndpointGetRandomOuts,
+	gomonerolight.EndpointImportRequest,
+	gomonerolight.EndpointSubmitRawTx,
+	gomonerolight.EndpointGetSubaddrs,
+	gomonerolight.EndpointUpsertSubaddrs,
+	gomonerolight.EndpointProvisionSubaddrs,
+	gomonerolight.EndpointGetVersion,
+	gomonerolight.EndpointDaemonStatus,
+}
+
+// Options holds the settings for a Collector.
+type Options struct {
+	Namespace    string                      // Prefixed to every metric's name. Defaults to "monero_light".
+	Buckets      []float64                   // Request duration histogram buckets, in seconds. Defaults to prometheus.DefBuckets.
+	AccountLabel func(address string) string // Returns the "account" label for an XMR address. Defaults to the address's first 12 characters.
+}
+
+// Collector records metrics for calls made by gomonerolight clients.
+// A single Collector can be shared by any number of clients.
+type Collector struct {
+	requests         *prometheus.CounterVec
+	duration         *prometheus.HistogramVec
+	retries          *prometheus.CounterVec
+	decodeFailures   *prometheus.CounterVec
+	blockchainHeight *prometheus.GaugeVec
+	scannedLag       *prometheus.GaugeVec
+
+	accountLabel func(address string) string
+}
+
+// NewCollector creates a new Collector using 'opts'.
+func NewCollector(opts Options) *Collector {
+	if opts.Namespace == "" {
+		opts.Namespace = "monero_light"
+	}
+
+	if opts.Buckets == nil {
+		opts.Buckets = prometheus.DefBuckets
+	}
+
+	if opts.AccountLabel == nil {
+		opts.AccountLabel = shortAddress
+	}
+
+	c := &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "requests_total",
+			Help:      "Calls made to light wallet server endpoints, by the class of their last status code (or \"error\" if there was none).",
+		}, []string{"endpoint", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Name:      "request_duration_seconds",
+			Help:      "How long calls to light wallet server endpoints took, including retries.",
+			Buckets:   opts.Buckets,
+		}, []string{"endpoint"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "retries_total",
+			Help:      "Requests sent to light wallet server endpoints after a call's first attempt failed.",
+		}, []string{"endpoint"}),
+		decodeFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Name:      "decode_failures_total",
+			Help:      "Responses from light wallet server endpoints that couldn't be decoded.",
+		}, []string{"endpoint"}),
+		blockchainHeight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: opts.Namespace,
+			Name:      "blockchain_height",
+			Help:      "The last blockchain height reported by each light wallet server.",
+		}, []string{"server"}),
+		scannedLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: opts.Namespace,
+			Name:      "scanned_height_lag_blocks",
+			Help:      "How many blocks behind the blockchain height each account has been scanned to.",
+		}, []string{"account"}),
+		accountLabel: opts.AccountLabel,
+	}
+
+	for _, endpoint := range endpoints {
+		c.duration.WithLabelValues(string(endpoint))
+		c.retries.WithLabelValues(string(endpoint))
+		c.decodeFailures.WithLabelValues(string(endpoint))
+	}
+
+	return c
+}
+
+// Register registers the Collector's metrics with 'reg'.
+func (c *Collector) Register(reg prometheus.Registerer) error {
+	return reg.Register(c)
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.retries.Describe(ch)
+	c.decodeFailures.Describe(ch)
+	c.blockchainHeight.Describe(ch)
+	c.scannedLag.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.retries.Collect(ch)
+	c.decodeFailures.Collect(ch)
+	c.blockchainHeight.Collect(ch)
+	c.scannedLag.Collect(ch)
+}
+
+// Interceptor returns an interceptor that records metrics for every
+// call made by a client. Pass it in Config.Interceptors.
+func (c *Collector) Interceptor() gomonerolight.Interceptor {
+	return func(ctx context.Context, call *gomonerolight.Call, next gomonerolight.Invoker) error {
+		start := time.Now()
+
+		err := next(ctx, call)
+
+		endpoint := string(call.Endpoint)
+
+		c.duration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+		c.requests.WithLabelValues(endpoint, codeClass(call.StatusCode)).Inc()
+
+		if call.Attempts > 1 {
+			c.retries.WithLabelValues(endpoint).Add(float64(call.Attempts - 1))
+		}
+
+		if errors.Is(err, gomonerolight.ErrorResponseUnmarshalFailed) {
+			c.decodeFailures.WithLabelValues(endpoint).Inc()
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var blockchainHeight, scannedHeight uint64
+
+		switch r := call.Response.(type) {
+		case *gomonerolight.GetAddressInfoResponse:
+			blockchainHeight, scannedHeight = r.BlockchainHeight, scannedBlockHeight(r.ScannedHeight, r.ScannedBlockHeight)
+		case *gomonerolight.GetAddressTxsResponse:
+			blockchainHeight, scannedHeight = r.BlockchainHeight, scannedBlockHeight(r.ScannedHeight, r.ScannedBlockHeight)
+		default:
+			return nil
+		}
+
+		if blockchainHeight == 0 {
+			return nil
+		}
+
+		c.blockchainHeight.WithLabelValues(call.ServerURL).Set(float64(blockchainHeight))
+
+		lag := 0.0
+		if scannedHeight < blockchainHeight {
+			lag = float64(blockchainHeight - scannedHeight)
+		}
+
+		c.scannedLag.WithLabelValues(c.accountLabel(call.Address)).Set(lag)
+
+		return nil
+	}
+}
+
+// scannedBlockHeight returns the block height an account has been
+// scanned to. Some servers (eg. MyMonero) report ScannedHeight as a
+// count of transactions, so ScannedBlockHeight is preferred.
+func scannedBlockHeight(scannedHeight, scannedBlockHeight uint64) uint64 {
+	if scannedBlockHeight != 0 {
+		return scannedBlockHeight
+	}
+
+	return scannedHeight
+}
+
+// codeClass returns the class of HTTP status code 'code' (eg. "4xx"),
+// or "error" if we didn't get a response.
+func codeClass(code int) string {
+	if code == 0 {
+		return "error"
+	}
+
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// shortAddress is the default Options.AccountLabel, which keeps
+// full addresses out of our metrics while still telling accounts apart.
+func shortAddress(address string) string {
+	if len(address) > 12 {
+		return address[:12]
+	}
+
+	return address
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package lwsprom
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gomonerolight "github.com/ChristianHering/Go-Monero-Light"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	tryCount := 1 //Number of times to send HTTP Service Unavailable
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/get_address_info":
+			if tryCount != 0 {
+				tryCount--
+
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+
+			err := json.NewEncoder(w).Encode(gomonerolight.GetAddressInfoResponse{
+				ScannedBlockHeight: 3222360,
+				BlockchainHeight:   3222370,
+			})
+			if err != nil {
+				t.Error("failed to marshal our response")
+			}
+		case "/get_address_txs":
+			_, err := w.Write([]byte("not json"))
+			if err != nil {
+				t.Error("failed to write our response")
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	collector := NewCollector(Options{})
+
+	reg := prometheus.NewRegistry()
+
+	err := collector.Register(reg)
+	if err != nil {
+		t.Fatal("Register() returned the error: ", err)
+	}
+
+	client, err := gomonerolight.NewClient(gomonerolight.Config{
+		Address:      "4AdUndXHHZ6cfufTMvppY6JwXNouMBzSkbLYfpAV5Usx3skxNgYeYTRj5UzqtReoS44qo9mtmXCqY45DJ852K5Jv2684Rge",
+		Interceptors: []gomonerolight.Interceptor{collector.Interceptor()},
+		RetryCount:   tryCount,
+		ServerURL:    ts.URL,
+		ViewKey:      "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	_, err = client.GetAddressInfo()
+	if err != nil {
+		t.Fatal("GetAddressInfo() returned the error: ", err)
+	}
+
+	_, _ = client.GetAddressTxs()
+	_, _ = client.GetUnspentOuts(&gomonerolight.GetUnspentOutsRequest{})
+
+	tests := []struct {
+		name     string
+		metric   prometheus.Collector
+		expected float64
+	}{
+		{"info requests", collector.requests.WithLabelValues("/get_address_info", "2xx"), 1},
+		{"info retries", collector.retries.WithLabelValues("/get_address_info"), 1},
+		{"unspent outs requests", collector.requests.WithLabelValues("/get_unspent_outs", "4xx"), 1},
+		{"txs decode failures", collector.decodeFailures.WithLabelValues("/get_address_txs"), 1},
+		{"blockchain height", collector.blockchainHeight.WithLabelValues(ts.URL), 3222370},
+		{"scanned lag", collector.scannedLag.WithLabelValues("4AdUndXHHZ6c"), 10},
+	}
+
+	for _, test := range tests {
+		value := testutil.ToFloat64(test.metric)
+		if value != test.expected {
+			t.Errorf("%s: the metric's value was %v, expected %v", test.name, value, test.expected)
+		}
+	}
+
+	// Every endpoint's duration histogram is initialized
+	if count := testutil.CollectAndCount(collector.duration); count != len(endpoints) {
+		t.Error("the duration histogram had this many series: ", count)
+	}
+}
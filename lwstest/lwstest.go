@@ -0,0 +1,817 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+// Package lwstest provides a fake light wallet server
+// for testing code that uses gomonerolight clients.
+//
+// Start a Server, script it, then point a client at it:
+//
+//	s := lwstest.NewServer()
+//	defer s.Close()
+//
+//	s.AddAccount("xmr_address", "xmr_view_key")
+//	hash, err := s.Receive("xmr_address", 1000000000000, true)
+//	...
+//	client, err := gomonerolight.NewClient(gomonerolight.Config{
+//		Address:   "xmr_address",
+//		ServerURL: s.URL,
+//		ViewKey:   "xmr_view_key",
+//	})
+//
+// The server only keeps track of what it's told. It doesn't scan a real
+// blockchain, so amounts, hashes and keys are made up, but consistent.
+package lwstest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	gomonerolight "github.com/ChristianHering/Go-Monero-Light"
+)
+
+const (
+	// PerByteFee is the fee the server asks for in /get_unspent_outs
+	PerByteFee = "24658"
+
+	// FeeMask is the fee mask the server sends in /get_unspent_outs
+	FeeMask = "10000"
+)
+
+var (
+	ErrorUnknownAccount     = errors.New("lwstest: no account exists for this address")
+	ErrorUnknownTransaction = errors.New("lwstest: no transaction exists with this hash")
+	ErrorInsufficientFunds  = errors.New("lwstest: the account's unspent outputs can't cover this amount")
+)
+
+// Server is a fake light wallet server that implements every
+// endpoint a gomonerolight client calls.
+type Server struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	height          uint64
+	requireApproval bool
+	accounts        map[string]*account
+	failures        map[gomonerolight.Endpoint][]int // HTTP status codes to fail upcoming requests with
+	submitted       []string
+	nonce           uint64 // Makes every hash and key we make up unique
+}
+
+type account struct {
+	viewKey     string
+	startHeight uint64
+	pending     bool // Waiting for approval, so requests are forbidden
+
+	importFee       string // If set, /import_request asks for this fee until it's paid
+	importPaid      bool
+	paymentAddress  string
+	paymentID       string
+	importRequested bool
+
+	subaddrs map[uint32][]gomonerolight.IndexRange // Minor index ranges, by major index
+
+	txs     []*gomonerolight.Transaction
+	outputs []*output
+}
+
+type output struct {
+	gomonerolight.Output
+
+	amount uint64
+	spent  bool
+}
+
+// NewServer starts a Server at a blockchain height of 1.
+// Call Close when you're done with it.
+func NewServer() *Server {
+	s := &Server{
+		height:   1,
+		accounts: map[string]*account{},
+		failures: map[gomonerolight.Endpoint][]int{},
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// AddAccount creates an account for 'address', starting at the current height.
+func (s *Server) AddAccount(address, viewKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accounts[address] = &account{viewKey: viewKey, startHeight: s.height}
+}
+
+// RequireApproval makes accounts created through /login wait for
+// Approve before their requests are answered, like some servers do.
+func (s *Server) RequireApproval(require bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requireApproval = require
+}
+
+// Approve lets the account for 'address' use the server.
+func (s *Server) Approve(address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[address]
+	if !ok {
+		return ErrorUnknownAccount
+	}
+
+	a.pending = false
+
+	return nil
+}
+
+// Height returns the server's blockchain height.
+func (s *Server) Height() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.height
+}
+
+// AdvanceHeight adds 'blocks' to the server's blockchain height.
+func (s *Server) AdvanceHeight(blocks uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.height += blocks
+}
+
+// Receive adds an incoming transaction of 'amount' piconero to the
+// account for 'address', returning its hash. Transactions in the
+// mempool don't have a height until SetMempool takes them out.
+func (s *Server) Receive(address string, amount uint64, mempool bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[address]
+	if !ok {
+		return "", ErrorUnknownAccount
+	}
+
+	tx := s.newTransaction(a, mempool)
+	s.receive(a, tx, amount)
+
+	return tx.Hash, nil
+}
+
+// Send adds an outgoing transaction of 'amount' piconero to the
+// account for 'address', returning its hash. The transaction spends
+// the account's unspent outputs and sends any change back to it.
+func (s *Server) Send(address string, amount uint64, mempool bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[address]
+	if !ok {
+		return "", ErrorUnknownAccount
+	}
+
+	var spending []*output
+	var total uint64
+
+	for _, o := range a.outputs {
+		if total >= amount {
+			break
+		}
+
+		if !o.spent {
+			spending = append(spending, o)
+			total += o.amount
+		}
+	}
+
+	if total < amount {
+		return "", ErrorInsufficientFunds
+	}
+
+	tx := s.newTransaction(a, mempool)
+
+	for _, o := range spending {
+		o.spent = true
+
+		tx.SpentOutputs = append(tx.SpentOutputs, gomonerolight.Spend{
+			Amount:      o.Amount,
+			KeyImage:    o.SpendKeyImages[0],
+			TxPublicKey: o.TxPublicKey,
+			OutIndex:    o.Index,
+			Mixin:       15,
+		})
+	}
+
+	tx.TotalSent = strconv.FormatUint(total, 10)
+
+	if total > amount {
+		s.receive(a, tx, total-amount)
+	}
+
+	return tx.Hash, nil
+}
+
+// SetMempool moves the transaction with 'hash' into or out of the
+// mempool. Transactions taken out are mined at the current height.
+func (s *Server) SetMempool(hash string, mempool bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range s.accounts {
+		for _, tx := range a.txs {
+			if tx.Hash != hash {
+				continue
+			}
+
+			tx.Mempool = mempool
+			tx.Height = 0
+
+			if !mempool {
+				tx.Height = s.height
+			}
+
+			for _, o := range a.outputs {
+				if o.TxHash == hash {
+					o.Height = tx.Height
+				}
+			}
+
+			return nil
+		}
+	}
+
+	return ErrorUnknownTransaction
+}
+
+// RequireImportFee makes /import_request ask the account for 'address'
+// to pay 'fee' piconero before its import is fulfilled, see PayImportFee.
+func (s *Server) RequireImportFee(address string, fee uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[address]
+	if !ok {
+		return ErrorUnknownAccount
+	}
+
+	a.importFee = strconv.FormatUint(fee, 10)
+	a.importPaid = false
+	a.paymentAddress = "lwstest_payment_address"
+	a.paymentID = s.newHex()[:16]
+
+	return nil
+}
+
+// PayImportFee marks the import fee of the account for 'address' as paid.
+func (s *Server) PayImportFee(address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.accounts[address]
+	if !ok {
+		return ErrorUnknownAccount
+	}
+
+	a.importPaid = true
+
+	return nil
+}
+
+// Fail makes the next 'times' requests to 'endpoint' fail
+// with HTTP status code 'status' (eg. 503, 403 or 400).
+func (s *Server) Fail(endpoint gomonerolight.Endpoint, status int, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < times; i++ {
+		s.failures[endpoint] = append(s.failures[endpoint], status)
+	}
+}
+
+// Submitted returns the raw transactions sent to /submit_raw_tx.
+func (s *Server) Submitted() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.submitted...)
+}
+
+// newTransaction adds a new, empty transaction to 'a'. s.mu must be held.
+func (s *Server) newTransaction(a *account, mempool bool) *gomonerolight.Transaction {
+	tx := &gomonerolight.Transaction{
+		ID:            uint64(len(a.txs)),
+		Hash:          s.newHex(),
+		Timestamp:     time.Now().UTC().Truncate(time.Second),
+		TotalReceived: "0",
+		TotalSent:     "0",
+		Mempool:       mempool,
+	}
+
+	if !mempool {
+		tx.Height = s.height
+	}
+
+	a.txs = append(a.txs, tx)
+
+	return tx
+}
+
+// receive adds an output of 'amount' to 'a' in 'tx'. s.mu must be held.
+func (s *Server) receive(a *account, tx *gomonerolight.Transaction, amount uint64) {
+	received, _ := strconv.ParseUint(tx.TotalReceived, 10, 64)
+	tx.TotalReceived = strconv.FormatUint(received+amount, 10)
+
+	a.outputs = append(a.outputs, &output{
+		Output: gomonerolight.Output{
+			TxID:           tx.ID,
+			Amount:         strconv.FormatUint(amount, 10),
+			GlobalIndex:    strconv.FormatUint(s.nonce, 10),
+			RingCT:         s.newHex(),
+			TxHash:         tx.Hash,
+			TxPrefixHash:   s.newHex(),
+			PublicKey:      s.newHex(),
+			TxPublicKey:    s.newHex(),
+			SpendKeyImages: []string{s.newHex()},
+			Timestamp:      tx.Timestamp.Format(time.RFC3339),
+			Height:         tx.Height,
+		},
+		amount: amount,
+	})
+}
+
+// newHex returns a unique, made up 32 byte hash or key. s.mu must be held.
+func (s *Server) newHex() string {
+	s.nonce++
+
+	sum := sha256.Sum256([]byte("lwstest" + strconv.FormatUint(s.nonce, 10)))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	endpoint := gomonerolight.Endpoint("/" + path.Base(r.URL.Path))
+
+	if failures := s.failures[endpoint]; len(failures) != 0 {
+		s.failures[endpoint] = failures[1:]
+
+		http.Error(w, http.StatusText(failures[0]), failures[0])
+
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	var status int
+	var response interface{}
+
+	switch endpoint {
+	case gomonerolight.EndpointLogin:
+		status, response = s.login(r)
+	case gomonerolight.EndpointGetAddressInfo:
+		status, response = s.getAddressInfo(r)
+	case gomonerolight.EndpointGetAddressTxs:
+		status, response = s.getAddressTxs(r)
+	case gomonerolight.EndpointGetUnspentOuts:
+		status, response = s.getUnspentOuts(r)
+	case gomonerolight.EndpointGetRandomOuts:
+		status, response = s.getRandomOuts(r)
+	case gomonerolight.EndpointImportRequest:
+		status, response = s.importRequest(r)
+	case gomonerolight.EndpointSubmitRawTx:
+		status, response = s.submitRawTx(r)
+	case gomonerolight.EndpointGetSubaddrs:
+		status, response = s.getSubaddrs(r)
+	case gomonerolight.EndpointUpsertSubaddrs:
+		status, response = s.upsertSubaddrs(r)
+	case gomonerolight.EndpointProvisionSubaddrs:
+		status, response = s.provisionSubaddrs(r)
+	default:
+		status = http.StatusNotFound
+	}
+
+	if status != http.StatusOK {
+		http.Error(w, http.StatusText(status), status)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// decode decodes the body of 'r' into 'request', returning
+// the HTTP status code to respond with if it can't.
+func decode(r *http.Request, request interface{}) int {
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusOK
+}
+
+// authenticate returns the account for 'address' if 'viewKey' is
+// right, or the HTTP status code to respond with if it isn't.
+func (s *Server) authenticate(address, viewKey string) (*account, int) {
+	a, ok := s.accounts[address]
+	if !ok || a.viewKey != viewKey || a.pending {
+		return nil, http.StatusForbidden
+	}
+
+	return a, http.StatusOK
+}
+
+func (s *Server) login(r *http.Request) (int, interface{}) {
+	req := &gomonerolight.LoginRequest{}
+	if status := decode(r, req); status != http.StatusOK {
+		return status, nil
+	}
+
+	a, ok := s.accounts[req.Address]
+	if !ok {
+		if !req.CreateAccount {
+			return http.StatusForbidden, nil
+		}
+
+		a = &account{viewKey: req.ViewKey, startHeight: s.height, pending: s.requireApproval}
+		s.accounts[req.Address] = a
+
+		return http.StatusOK, &gomonerolight.LoginResponse{
+			NewAddress:       true,
+			GeneratedLocally: req.GeneratedLocally,
+			StartHeight:      a.startHeight,
+		}
+	}
+
+	if a.viewKey != req.ViewKey {
+		return http.StatusForbidden, nil
+	}
+
+	// Like monero-lws, accounts waiting for approval are still new
+	if a.pending {
+		if !req.CreateAccount {
+			return http.StatusForbidden, nil
+		}
+
+		return http.StatusOK, &gomonerolight.LoginResponse{
+			NewAddress:       true,
+			GeneratedLocally: req.GeneratedLocally,
+			StartHeight:      a.startHeight,
+		}
+	}
+
+	return http.StatusOK, &gomonerolight.LoginResponse{StartHeight: a.startHeight}
+}
+
+func (s *Server) getAddressInfo(r *http.Request) (int, interface{}) {
+	req := &gomonerolight.StandardRequest{}
+	if status := decode(r, req); status != http.StatusOK {
+		return status, nil
+	}
+
+	a, status := s.authenticate(req.Address, req.ViewKey)
+	if status != http.StatusOK {
+		return status, nil
+	}
+
+	var locked, received, sent uint64
+	var spends []gomonerolight.Spend
+
+	for _, tx := range a.txs {
+		r, _ := strconv.ParseUint(tx.TotalReceived, 10, 64)
+		t, _ := strconv.ParseUint(tx.TotalSent, 10, 64)
+
+		if tx.Mempool {
+			locked += r
+		}
+
+		received += r
+		sent += t
+		spends = append(spends, tx.SpentOutputs...)
+	}
+
+	return http.StatusOK, &gomonerolight.GetAddressInfoResponse{
+		LockedFunds:        strconv.FormatUint(locked, 10),
+		TotalReceived:      strconv.FormatUint(received, 10),
+		TotalSent:          strconv.FormatUint(sent, 10),
+		ScannedHeight:      s.height,
+		ScannedBlockHeight: s.height,
+		StartHeight:        a.startHeight,
+		TransactionHeight:  s.height,
+		BlockchainHeight:   s.height,
+		SpentOutputs:       spends,
+	}
+}
+
+func (s *Server) getAddressTxs(r *http.Request) (int, interface{}) {
+	req := &gomonerolight.StandardRequest{}
+	if status := decode(r, req); status != http.StatusOK {
+		return status, nil
+	}
+
+	a, status := s.authenticate(req.Address, req.ViewKey)
+	if status != http.StatusOK {
+		return status, nil
+	}
+
+	var received uint64
+	txs := []gomonerolight.Transaction{}
+
+	for _, tx := range a.txs {
+		r, _ := strconv.ParseUint(tx.TotalReceived, 10, 64)
+		received += r
+
+		txs = append(txs, *tx)
+	}
+
+	return http.StatusOK, &gomonerolight.GetAddressTxsResponse{
+		TotalReceived:      strconv.FormatUint(received, 10),
+		ScannedHeight:      s.height,
+		ScannedBlockHeight: s.height,
+		StartHeight:        a.startHeight,
+		BlockchainHeight:   s.height,
+		Transactions:       txs,
+	}
+}
+
+func (s *Server) getUnspentOuts(r *http.Request) (int, interface{}) {
+	req := &gomonerolight.GetUnspentOutsRequest{}
+	if status := decode(r, req); status != http.StatusOK {
+		return status, nil
+	}
+
+	a, status := s.authenticate(req.Address, req.ViewKey)
+	if status != http.StatusOK {
+		return status, nil
+	}
+
+	var total uint64
+	outputs := []gomonerolight.Output{}
+
+	for _, o := range a.outputs {
+		if !o.spent {
+			total += o.amount
+			outputs = append(outputs, o.Output)
+		}
+	}
+
+	amount, _ := strconv.ParseUint(req.Amount, 10, 64)
+	if amount > total {
+		return http.StatusBadRequest, nil
+	}
+
+	return http.StatusOK, &gomonerolight.GetUnspentOutsResponse{
+		PerByteFee: PerByteFee,
+		FeeMask:    FeeMask,
+		Amount:     strconv.FormatUint(total, 10),
+		Outputs:    outputs,
+	}
+}
+
+func (s *Server) getRandomOuts(r *http.Request) (int, interface{}) {
+	req := &gomonerolight.GetRandomOutsRequest{}
+	if status := decode(r, req); status != http.StatusOK {
+		return status, nil
+	}
+
+	response := &gomonerolight.GetRandomOutsResponse{AmountOuts: []gomonerolight.RandomOutputs{}}
+
+	for _, amount := range req.Amounts {
+		outs := gomonerolight.RandomOutputs{Amount: amount, Outputs: []gomonerolight.RandomOutput{}}
+
+		for i := uint32(0); i < req.Count; i++ {
+			outs.Outputs = append(outs.Outputs, gomonerolight.RandomOutput{
+				GlobalIndex: strconv.FormatUint(s.nonce, 10),
+				PublicKey:   s.newHex(),
+				RingCT:      s.newHex(),
+			})
+		}
+
+		response.AmountOuts = append(response.AmountOuts, outs)
+	}
+
+	return http.StatusOK, response
+}
+
+func (s *Server) importRequest(r *http.Request) (int, interface{}) {
+	req := &gomonerolight.ImportRequestRequest{}
+	if status := decode(r, req); status != http.StatusOK {
+		return status, nil
+	}
+
+	a, status := s.authenticate(req.Address, req.ViewKey)
+	if status != http.StatusOK {
+		return status, nil
+	}
+
+	response := &gomonerolight.ImportRequestResponse{NewRequest: !a.importRequested}
+	a.importRequested = true
+
+	if a.importFee != "" && !a.importPaid {
+		response.PaymentAddress = a.paymentAddress
+		response.PaymentID = a.paymentID
+		response.ImportFee = a.importFee
+		response.Status = "Payment required"
+
+		return http.StatusOK, response
+	}
+
+	var from uint64
+	if req.FromHeight != nil {
+		from = *req.FromHeight
+	}
+
+	if from < a.startHeight {
+		a.startHeight = from
+	}
+
+	response.RequestFulfilled = true
+	response.Status = "Approved"
+
+	return http.StatusOK, response
+}
+
+func (s *Server) submitRawTx(r *http.Request) (int, interface{}) {
+	req := &gomonerolight.SubmitRawTxRequest{}
+	if status := decode(r, req); status != http.StatusOK {
+		return status, nil
+	}
+
+	if _, err := hex.DecodeString(req.Tx); err != nil || req.Tx == "" {
+		return http.StatusBadRequest, nil
+	}
+
+	s.submitted = append(s.submitted, req.Tx)
+
+	return http.StatusOK, &gomonerolight.SubmitRawTxResponse{Status: "OK"}
+}
+
+func (s *Server) getSubaddrs(r *http.Request) (int, interface{}) {
+	req := &gomonerolight.StandardRequest{}
+	if status := decode(r, req); status != http.StatusOK {
+		return status, nil
+	}
+
+	a, status := s.authenticate(req.Address, req.ViewKey)
+	if status != http.StatusOK {
+		return status, nil
+	}
+
+	return http.StatusOK, &gomonerolight.GetSubaddrsResponse{AllSubaddrs: a.allSubaddrs()}
+}
+
+func (s *Server) upsertSubaddrs(r *http.Request) (int, interface{}) {
+	req := &gomonerolight.UpsertSubaddrsRequest{}
+	if status := decode(r, req); status != http.StatusOK {
+		return status, nil
+	}
+
+	a, status := s.authenticate(req.Address, req.ViewKey)
+	if status != http.StatusOK {
+		return status, nil
+	}
+
+	response := &gomonerolight.UpsertSubaddrsResponse{NewSubaddrs: []gomonerolight.Subaddrs{}}
+
+	for _, subaddrs := range req.Subaddrs {
+		var added []gomonerolight.IndexRange
+
+		for _, r := range subaddrs.Ranges {
+			if r[0] > r[1] {
+				return http.StatusBadRequest, nil
+			}
+
+			added = append(added, a.addSubaddrs(subaddrs.Major, r)...)
+		}
+
+		if len(added) != 0 {
+			response.NewSubaddrs = append(response.NewSubaddrs, gomonerolight.Subaddrs{Major: subaddrs.Major, Ranges: added})
+		}
+	}
+
+	if req.GetAll {
+		response.AllSubaddrs = a.allSubaddrs()
+	}
+
+	return http.StatusOK, response
+}
+
+func (s *Server) provisionSubaddrs(r *http.Request) (int, interface{}) {
+	req := &gomonerolight.ProvisionSubaddrsRequest{}
+	if status := decode(r, req); status != http.StatusOK {
+		return status, nil
+	}
+
+	a, status := s.authenticate(req.Address, req.ViewKey)
+	if status != http.StatusOK {
+		return status, nil
+	}
+
+	if req.NumMajor == 0 || req.NumMinor == 0 || req.MajorIndex+req.NumMajor < req.MajorIndex || req.MinorIndex+req.NumMinor < req.MinorIndex {
+		return http.StatusBadRequest, nil
+	}
+
+	response := &gomonerolight.ProvisionSubaddrsResponse{NewSubaddrs: []gomonerolight.Subaddrs{}}
+
+	for major := req.MajorIndex; major < req.MajorIndex+req.NumMajor; major++ {
+		added := a.addSubaddrs(major, gomonerolight.IndexRange{req.MinorIndex, req.MinorIndex + req.NumMinor - 1})
+
+		if len(added) != 0 {
+			response.NewSubaddrs = append(response.NewSubaddrs, gomonerolight.Subaddrs{Major: major, Ranges: added})
+		}
+	}
+
+	if req.GetAll {
+		response.AllSubaddrs = a.allSubaddrs()
+	}
+
+	return http.StatusOK, response
+}
+
+// allSubaddrs returns every subaddress range registered for 'a', by major index.
+func (a *account) allSubaddrs() []gomonerolight.Subaddrs {
+	all := []gomonerolight.Subaddrs{}
+
+	for major, ranges := range a.subaddrs {
+		all = append(all, gomonerolight.Subaddrs{Major: major, Ranges: ranges})
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Major < all[j].Major })
+
+	return all
+}
+
+// addSubaddrs registers the minor indexes in 'r' under 'major',
+// returning the ranges that weren't already registered.
+func (a *account) addSubaddrs(major uint32, r gomonerolight.IndexRange) []gomonerolight.IndexRange {
+	if a.subaddrs == nil {
+		a.subaddrs = map[uint32][]gomonerolight.IndexRange{}
+	}
+
+	existing := a.subaddrs[major]
+
+	// Find the parts of 'r' that no existing range covers
+	var added []gomonerolight.IndexRange
+
+	next := uint64(r[0])
+
+	for _, e := range existing {
+		if uint64(e[1]) < next || e[0] > r[1] {
+			continue
+		}
+
+		if uint64(e[0]) > next {
+			added = append(added, gomonerolight.IndexRange{uint32(next), e[0] - 1})
+		}
+
+		next = uint64(e[1]) + 1
+	}
+
+	if next <= uint64(r[1]) {
+		added = append(added, gomonerolight.IndexRange{uint32(next), r[1]})
+	}
+
+	// Merge 'r' into the existing ranges, which are kept sorted and apart
+	merged := append(append([]gomonerolight.IndexRange(nil), existing...), r)
+	sort.Slice(merged, func(i, j int) bool { return merged[i][0] < merged[j][0] })
+
+	ranges := merged[:1]
+
+	for _, m := range merged[1:] {
+		last := &ranges[len(ranges)-1]
+
+		if uint64(m[0]) <= uint64(last[1])+1 {
+			if m[1] > last[1] {
+				last[1] = m[1]
+			}
+
+			continue
+		}
+
+		ranges = append(ranges, m)
+	}
+
+	a.subaddrs[major] = ranges
+
+	return added
+}
@@ -0,0 +1,241 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package lwstest
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	gomonerolight "github.com/ChristianHering/Go-Monero-Light"
+)
+
+func newClient(t *testing.T, s *Server, address string) *gomonerolight.Client {
+	client, err := gomonerolight.NewClient(gomonerolight.Config{
+		Address:   address,
+		ServerURL: s.URL,
+		ViewKey:   "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	return client
+}
+
+func TestServer(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddAccount("xmr_address", "xmr_view_key")
+	s.AdvanceHeight(99)
+
+	client := newClient(t, s, "xmr_address")
+
+	in, err := s.Receive("xmr_address", 3000, true)
+	if err != nil {
+		t.Fatal("Receive() returned the error: ", err)
+	}
+
+	info, err := client.GetAddressInfo()
+	if err != nil {
+		t.Fatal("GetAddressInfo() returned the error: ", err)
+	}
+
+	if info.LockedFunds != "3000" || info.TotalReceived != "3000" || info.BlockchainHeight != 100 {
+		t.Error("GetAddressInfo() returned ", info)
+	}
+
+	err = s.SetMempool(in, false)
+	if err != nil {
+		t.Fatal("SetMempool() returned the error: ", err)
+	}
+
+	out, err := s.Send("xmr_address", 1000, false)
+	if err != nil {
+		t.Fatal("Send() returned the error: ", err)
+	}
+
+	txs, err := client.GetAddressTxs()
+	if err != nil {
+		t.Fatal("GetAddressTxs() returned the error: ", err)
+	}
+
+	if len(txs.Transactions) != 2 || txs.Transactions[0].Mempool || txs.Transactions[0].Height != 100 {
+		t.Fatal("GetAddressTxs() returned ", txs)
+	}
+
+	if sent := txs.Transactions[1]; sent.Hash != out || sent.TotalSent != "3000" || sent.TotalReceived != "2000" || len(sent.SpentOutputs) != 1 {
+		t.Error("the outgoing transaction was ", sent)
+	}
+
+	outs, err := client.GetUnspentOuts(&gomonerolight.GetUnspentOutsRequest{Amount: "2000"})
+	if err != nil {
+		t.Fatal("GetUnspentOuts() returned the error: ", err)
+	}
+
+	if outs.Amount != "2000" || len(outs.Outputs) != 1 || outs.PerByteFee != PerByteFee {
+		t.Error("GetUnspentOuts() returned ", outs)
+	}
+
+	_, err = client.GetUnspentOuts(&gomonerolight.GetUnspentOutsRequest{Amount: "2001"})
+	if !errors.Is(err, gomonerolight.ErrorStatusCodeNotOK) {
+		t.Error("GetUnspentOuts() didn't fail for an amount we can't afford: ", err)
+	}
+
+	random, err := client.GetRandomOuts(&gomonerolight.GetRandomOutsRequest{Count: 16, Amounts: []string{"0"}})
+	if err != nil {
+		t.Fatal("GetRandomOuts() returned the error: ", err)
+	}
+
+	if len(random.AmountOuts) != 1 || len(random.AmountOuts[0].Outputs) != 16 {
+		t.Error("GetRandomOuts() returned ", random)
+	}
+
+	_, err = client.SubmitRawTx(&gomonerolight.SubmitRawTxRequest{Tx: "0102"})
+	if err != nil {
+		t.Fatal("SubmitRawTx() returned the error: ", err)
+	}
+
+	if submitted := s.Submitted(); len(submitted) != 1 || submitted[0] != "0102" {
+		t.Error("the submitted transactions were ", submitted)
+	}
+}
+
+func TestServerAccounts(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.RequireApproval(true)
+
+	client := newClient(t, s, "xmr_address")
+
+	_, err := client.Login(&gomonerolight.LoginRequest{})
+	if !errors.Is(err, gomonerolight.ErrorStatusCodeNotOK) {
+		t.Error("Login() didn't fail for an account that doesn't exist: ", err)
+	}
+
+	login, err := client.Login(&gomonerolight.LoginRequest{CreateAccount: true})
+	if err != nil || !login.NewAddress {
+		t.Fatal("Login() returned ", login, err)
+	}
+
+	_, err = client.GetAddressInfo()
+	if !errors.Is(err, gomonerolight.ErrorAccountPendingApproval) {
+		t.Error("GetAddressInfo() didn't fail for an account waiting for approval: ", err)
+	}
+
+	login, err = client.Login(&gomonerolight.LoginRequest{CreateAccount: true})
+	if err != nil || !login.NewAddress {
+		t.Error("Login() returned ", login, err)
+	}
+
+	err = s.Approve("xmr_address")
+	if err != nil {
+		t.Fatal("Approve() returned the error: ", err)
+	}
+
+	err = s.RequireImportFee("xmr_address", 5000)
+	if err != nil {
+		t.Fatal("RequireImportFee() returned the error: ", err)
+	}
+
+	imp, err := client.ImportRequest()
+	if err != nil {
+		t.Fatal("ImportRequest() returned the error: ", err)
+	}
+
+	if imp.RequestFulfilled || imp.ImportFee != "5000" || imp.PaymentAddress == "" || !imp.NewRequest {
+		t.Error("ImportRequest() returned ", imp)
+	}
+
+	err = s.PayImportFee("xmr_address")
+	if err != nil {
+		t.Fatal("PayImportFee() returned the error: ", err)
+	}
+
+	imp, err = client.ImportRequest()
+	if err != nil || !imp.RequestFulfilled || imp.NewRequest {
+		t.Error("ImportRequest() returned ", imp, err)
+	}
+}
+
+func TestServerFailures(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddAccount("xmr_address", "xmr_view_key")
+	s.Fail(gomonerolight.EndpointGetAddressInfo, http.StatusServiceUnavailable, 1)
+	s.Fail(gomonerolight.EndpointGetAddressInfo, http.StatusForbidden, 1)
+
+	client := newClient(t, s, "xmr_address")
+
+	var apiErr *gomonerolight.APIError
+
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusForbidden, http.StatusOK} {
+		_, err := client.GetAddressInfo()
+
+		if status == http.StatusOK {
+			if err != nil {
+				t.Error("GetAddressInfo() returned the error: ", err)
+			}
+
+			continue
+		}
+
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != status {
+			t.Errorf("GetAddressInfo() should have failed with HTTP %d, it returned: %v", status, err)
+		}
+	}
+}
+
+func TestServerSubaddrs(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddAccount("xmr_address", "xmr_view_key")
+
+	client := newClient(t, s, "xmr_address")
+
+	upsert, err := client.UpsertSubaddrs(&gomonerolight.UpsertSubaddrsRequest{
+		Subaddrs: []gomonerolight.Subaddrs{{Major: 0, Ranges: []gomonerolight.IndexRange{{1, 10}, {20, 29}}}},
+	})
+	if err != nil {
+		t.Fatal("UpsertSubaddrs() returned the error: ", err)
+	}
+
+	if len(upsert.NewSubaddrs) != 1 || len(upsert.NewSubaddrs[0].Ranges) != 2 {
+		t.Error("UpsertSubaddrs() returned ", upsert)
+	}
+
+	provision, err := client.ProvisionSubaddrs(&gomonerolight.ProvisionSubaddrsRequest{MinorIndex: 5, NumMajor: 2, NumMinor: 20, GetAll: true})
+	if err != nil {
+		t.Fatal("ProvisionSubaddrs() returned the error: ", err)
+	}
+
+	// Only the indexes that weren't registered yet are new
+	want := []gomonerolight.Subaddrs{
+		{Major: 0, Ranges: []gomonerolight.IndexRange{{11, 19}}},
+		{Major: 1, Ranges: []gomonerolight.IndexRange{{5, 24}}},
+	}
+
+	if !reflect.DeepEqual(provision.NewSubaddrs, want) {
+		t.Error("ProvisionSubaddrs() registered ", provision.NewSubaddrs)
+	}
+
+	all, err := client.GetSubaddrs()
+	if err != nil {
+		t.Fatal("GetSubaddrs() returned the error: ", err)
+	}
+
+	want = []gomonerolight.Subaddrs{
+		{Major: 0, Ranges: []gomonerolight.IndexRange{{1, 29}}},
+		{Major: 1, Ranges: []gomonerolight.IndexRange{{5, 24}}},
+	}
+
+	if !reflect.DeepEqual(all.AllSubaddrs, want) || !reflect.DeepEqual(provision.AllSubaddrs, want) {
+		t.Error("GetSubaddrs() returned ", all.AllSubaddrs)
+	}
+}
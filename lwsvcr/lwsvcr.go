@@ -0,0 +1,350 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+// Package lwsvcr records the traffic between gomonerolight clients and
+// real light wallet servers, so tests can replay it later without a server.
+//
+// Record some fixtures by sending requests through a Recorder:
+//
+//	client, err := gomonerolight.NewClient(gomonerolight.Config{
+//		HTTPClient: &http.Client{Transport: lwsvcr.NewRecorder("testdata/lws", nil)},
+//		...
+//	})
+//
+// Then replay them in your tests:
+//
+//	replayer, err := lwsvcr.NewReplayer("testdata/lws")
+//	...
+//	client, err := gomonerolight.NewClient(gomonerolight.Config{
+//		HTTPClient: &http.Client{Transport: replayer},
+//		...
+//	})
+//
+// Fixtures are stored as one JSON file per endpoint (eg. get_address_info.json),
+// with secrets like view keys scrubbed from requests. Responses are stored
+// with their headers, and bodies that aren't JSON are base64 encoded.
+package lwsvcr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Scrubbed replaces secrets in recorded requests
+const Scrubbed = "[SCRUBBED]"
+
+var ErrorRequestNotJSON = errors.New("lwsvcr: only requests with JSON bodies can be recorded or replayed")
+
+// scrubbedFields are the request fields whose values are replaced with Scrubbed
+var scrubbedFields = map[string]bool{
+	"auth":      true,
+	"spend_key": true,
+	"token":     true,
+	"view_key":  true,
+}
+
+// EncodingBase64 marks an Interaction's Body as base64 encoded
+const EncodingBase64 = "base64"
+
+// Interaction is a single recorded request and the server's response.
+type Interaction struct {
+	Request  json.RawMessage `json:"request"`            // The request's body, with secrets scrubbed
+	Status   int             `json:"status"`             // The response's HTTP status code
+	Header   http.Header     `json:"header,omitempty"`   // The response's headers (eg. Content-Type), but Content-Length
+	Response json.RawMessage `json:"response,omitempty"` // The response's body, if it was JSON
+	Body     string          `json:"body,omitempty"`     // The response's body, if it wasn't JSON (eg. epee)
+	Encoding string          `json:"encoding,omitempty"` // How Body is encoded: EncodingBase64, or "" if it's stored as is
+}
+
+// body returns the response body recorded in 'i'.
+func (i *Interaction) body() ([]byte, error) {
+	if len(i.Response) != 0 {
+		return i.Response, nil
+	}
+
+	if i.Encoding == EncodingBase64 {
+		return base64.StdEncoding.DecodeString(i.Body)
+	}
+
+	return []byte(i.Body), nil
+}
+
+// UnmatchedRequestError is returned (wrapped in a *gomonerolight.APIError)
+// by a Replayer when it has no fixture left for a request.
+type UnmatchedRequestError struct {
+	Endpoint string          // The endpoint the request was sent to (eg. "/login")
+	Request  json.RawMessage // The request's body, with secrets scrubbed
+}
+
+func (e *UnmatchedRequestError) Error() string {
+	return "lwsvcr: no recorded interaction matches the request to " + e.Endpoint + ": " + string(e.Request)
+}
+
+// Recorder is an http.RoundTripper that saves every
+// request it sends, and its response, to fixture files.
+type Recorder struct {
+	dir  string
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions map[string][]Interaction // Keyed by fixture file name
+}
+
+// NewRecorder creates a Recorder that sends requests with 'next'
+// (or http.DefaultTransport if it's nil) and saves them in 'dir'.
+// Existing fixtures in 'dir' are overwritten.
+func NewRecorder(dir string, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Recorder{
+		dir:          dir,
+		next:         next,
+		interactions: map[string][]Interaction{},
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := readRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := Interaction{Request: request, Status: resp.StatusCode, Header: resp.Header.Clone()}
+	interaction.Header.Del("Content-Length")
+
+	// Binary bodies (eg. epee) wouldn't survive being stored as a string
+	if json.Valid(body) {
+		interaction.Response = bytes.TrimSpace(body)
+	} else if len(body) != 0 {
+		interaction.Body = base64.StdEncoding.EncodeToString(body)
+		interaction.Encoding = EncodingBase64
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := fixtureName(req)
+	r.interactions[name] = append(r.interactions[name], interaction)
+
+	err = os.MkdirAll(r.dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.MarshalIndent(r.interactions[name], "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(r.dir, name), append(b, '\n'), 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Replayer is an http.RoundTripper that answers requests with
+// the responses saved by a Recorder, without contacting a server.
+//
+// Each recorded interaction is served once, in the order they were
+// recorded. Requests with no interaction left fail with an *UnmatchedRequestError.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction // Keyed by fixture file name
+	used         map[string][]bool
+}
+
+// NewReplayer creates a Replayer serving the fixtures in 'dir'.
+func NewReplayer(dir string) (*Replayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replayer{
+		interactions: map[string][]Interaction{},
+		used:         map[string][]bool{},
+	}
+
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var interactions []Interaction
+
+		err = json.Unmarshal(b, &interactions)
+		if err != nil {
+			return nil, err
+		}
+
+		name := filepath.Base(file)
+		r.interactions[name] = interactions
+		r.used[name] = make([]bool, len(interactions))
+	}
+
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, err := readRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var want interface{}
+
+	err = json.Unmarshal(request, &want)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := fixtureName(req)
+
+	for i, interaction := range r.interactions[name] {
+		if r.used[name][i] {
+			continue
+		}
+
+		var got interface{}
+
+		err = json.Unmarshal(interaction.Request, &got)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			continue
+		}
+
+		body, err := interaction.body()
+		if err != nil {
+			return nil, err
+		}
+
+		r.used[name][i] = true
+
+		header := interaction.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+
+		return &http.Response{
+			Status:        http.StatusText(interaction.Status),
+			StatusCode:    interaction.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, &UnmatchedRequestError{Endpoint: req.URL.Path, Request: request}
+}
+
+// Unused returns the endpoints with recorded interactions that weren't
+// replayed, so tests can check every fixture they expected was used.
+func (r *Replayer) Unused() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []string
+
+	for name, used := range r.used {
+		for _, u := range used {
+			if !u {
+				unused = append(unused, "/"+strings.TrimSuffix(name, ".json"))
+
+				break
+			}
+		}
+	}
+
+	sort.Strings(unused)
+
+	return unused
+}
+
+// readRequest returns the body of 'req' with secrets scrubbed, leaving
+// the body in place to be sent.
+func readRequest(req *http.Request) (json.RawMessage, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	var v interface{}
+
+	err := json.Unmarshal(body, &v)
+	if err != nil {
+		return nil, ErrorRequestNotJSON
+	}
+
+	return json.Marshal(scrub(v))
+}
+
+// scrub replaces the values of scrubbedFields in 'v' with Scrubbed.
+func scrub(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if scrubbedFields[key] {
+				v[key] = Scrubbed
+			} else {
+				v[key] = scrub(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = scrub(value)
+		}
+	}
+
+	return v
+}
+
+// fixtureName returns the name of the file requests like 'req' are saved in.
+func fixtureName(req *http.Request) string {
+	return path.Base(req.URL.Path) + ".json"
+}
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package lwsvcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gomonerolight "github.com/ChristianHering/Go-Monero-Light"
+)
+
+func TestRecordReplay(t *testing.T) {
+	const viewKey = "f359631075708155cc3d92a32b75a7d02a5dcf27756707b47a2b31b21c389501"
+
+	dir := t.TempDir()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/import_request" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("Forbidden"))
+
+			return
+		}
+
+		err := json.NewEncoder(w).Encode(gomonerolight.GetAddressInfoResponse{
+			TotalReceived:    "1000000000000",
+			BlockchainHeight: 3222370,
+		})
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}))
+
+	newClient := func(transport http.RoundTripper) *gomonerolight.Client {
+		client, err := gomonerolight.NewClient(gomonerolight.Config{
+			Address:    "xmr_address",
+			HTTPClient: &http.Client{Transport: transport},
+			ServerURL:  ts.URL,
+			ViewKey:    viewKey,
+		})
+		if err != nil {
+			t.Fatal("NewClient() returned the error: ", err)
+		}
+
+		return client
+	}
+
+	client := newClient(NewRecorder(dir, nil))
+
+	_, err := client.GetAddressInfo()
+	if err != nil {
+		t.Fatal("GetAddressInfo() returned the error: ", err)
+	}
+
+	_, err = client.ImportRequest()
+	if !errors.Is(err, gomonerolight.ErrorStatusCodeNotOK) {
+		t.Fatal("ImportRequest() returned the error: ", err)
+	}
+
+	ts.Close()
+
+	b, err := os.ReadFile(filepath.Join(dir, "get_address_info.json"))
+	if err != nil {
+		t.Fatal("the fixture wasn't saved: ", err)
+	}
+
+	if strings.Contains(string(b), viewKey) || !strings.Contains(string(b), Scrubbed) {
+		t.Error("the view key wasn't scrubbed from our fixture: ", string(b))
+	}
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal("NewReplayer() returned the error: ", err)
+	}
+
+	client = newClient(replayer)
+
+	info, err := client.GetAddressInfo()
+	if err != nil {
+		t.Fatal("GetAddressInfo() returned the error: ", err)
+	}
+
+	if info.TotalReceived != "1000000000000" || info.BlockchainHeight != 3222370 {
+		t.Error("the replayed response was ", info)
+	}
+
+	if unused := replayer.Unused(); len(unused) != 1 || unused[0] != "/import_request" {
+		t.Error("the unused fixtures were ", unused)
+	}
+
+	_, err = client.ImportRequest()
+	if !errors.Is(err, gomonerolight.ErrorStatusCodeNotOK) {
+		t.Fatal("ImportRequest() returned the error: ", err)
+	}
+
+	// Every interaction has been replayed, so this request is unmatched
+	var unmatched *UnmatchedRequestError
+
+	_, err = client.GetAddressInfo()
+	if !errors.As(err, &unmatched) || unmatched.Endpoint != "/get_address_info" {
+		t.Error("GetAddressInfo() returned the error: ", err)
+	}
+}
+
+func TestScrub(t *testing.T) {
+	var v interface{}
+
+	err := json.Unmarshal([]byte(`{"address":"xmr_address","token":"secret_token","subaddrs":[{"auth":"secret_auth"}]}`), &v)
+	if err != nil {
+		t.Fatal("failed to unmarshal our request")
+	}
+
+	b, err := json.Marshal(scrub(v))
+	if err != nil {
+		t.Fatal("failed to marshal the scrubbed request")
+	}
+
+	if strings.Contains(string(b), "secret") || !strings.Contains(string(b), "xmr_address") {
+		t.Error("scrub() returned: ", string(b))
+	}
+}
+
+func TestRecordReplayBinary(t *testing.T) {
+	body := []byte{0x01, 0x11, 0x01, 0x01, 0xff, 0xfe, 0x00, 0x80}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("X-Server", "monero-lws")
+
+		_, _ = w.Write(body)
+	}))
+
+	dir := t.TempDir()
+
+	post := func(client *http.Client) *http.Response {
+		resp, err := client.Post(ts.URL+"/get_address_info", "application/json", strings.NewReader(`{"address":"xmr_address"}`))
+		if err != nil {
+			t.Fatal("the request returned the error: ", err)
+		}
+
+		return resp
+	}
+
+	resp := post(&http.Client{Transport: NewRecorder(dir, nil)})
+	_ = resp.Body.Close()
+
+	ts.Close()
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal("NewReplayer() returned the error: ", err)
+	}
+
+	resp = post(&http.Client{Transport: replayer})
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil || !bytes.Equal(b, body) {
+		t.Errorf("the replayed body was %x, wanted %x", b, body)
+	}
+
+	if resp.Header.Get("Content-Type") != "application/octet-stream" || resp.Header.Get("X-Server") != "monero-lws" {
+		t.Error("the replayed headers were: ", resp.Header)
+	}
+}
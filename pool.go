@@ -0,0 +1,305 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// poolFailureThreshold is the number of consecutive
+	// failures before a server is ejected from our pool.
+	poolFailureThreshold = 3
+
+	// poolCooldown is how long an ejected server is left
+	// alone before a request is sent to probe it back in.
+	poolCooldown = 30 * time.Second
+
+	// poolMaxHeightLag is how many blocks a server's BlockchainHeight
+	// can trail the highest one we've seen before it's avoided.
+	poolMaxHeightLag = 10
+
+	// poolDecay is the weight given to the newest sample
+	// when averaging a server's latency and error rate.
+	poolDecay = 0.2
+)
+
+// ServerState is the state of a server's circuit breaker.
+type ServerState int
+
+const (
+	ServerHealthy ServerState = iota // Requests are sent to the server
+	ServerEjected                    // The server failed too often and is left alone until its cooldown passes
+	ServerProbing                    // The server's cooldown passed and a request was sent to see if it has recovered
+)
+
+func (s ServerState) String() string {
+	switch s {
+	case ServerHealthy:
+		return "healthy"
+	case ServerEjected:
+		return "ejected"
+	case ServerProbing:
+		return "probing"
+	}
+
+	return "unknown"
+}
+
+// ServerStatus is a snapshot of what we know about one of our servers.
+type ServerStatus struct {
+	URL              string
+	State            ServerState
+	Latency          time.Duration // A moving average of the server's response time
+	ErrorRate        float64       // A moving average of the server's failures, from 0 to 1
+	BlockchainHeight uint64        // The last BlockchainHeight the server reported
+}
+
+// serverPool routes requests to the healthiest of several light wallet
+// servers. Servers that fail poolFailureThreshold times in a row are
+// ejected, then probed with a single request once poolCooldown passes.
+type serverPool struct {
+	mu      sync.Mutex
+	servers []*poolServer
+	now     func() time.Time
+}
+
+type poolServer struct {
+	ServerStatus
+
+	failures int       // Consecutive failures
+	ejected  time.Time // When the server was last ejected
+}
+
+func newServerPool(urls []string) *serverPool {
+	p := &serverPool{now: time.Now}
+
+	for _, url := range urls {
+		p.servers = append(p.servers, &poolServer{ServerStatus: ServerStatus{URL: url}})
+	}
+
+	return p
+}
+
+// pick returns the URL of the server our next request should be
+// sent to, avoiding 'last' (the server that just failed) if we can.
+func (p *serverPool) pick(last string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+
+	// Probe a single ejected server once its cooldown passes. Probes
+	// that never finish (eg. their context was canceled) are retried
+	// after another cooldown.
+	for _, s := range p.servers {
+		if s.State != ServerHealthy && now.Sub(s.ejected) >= poolCooldown && s.URL != last {
+			s.State = ServerProbing
+			s.ejected = now
+
+			return s.URL
+		}
+	}
+
+	var maxHeight uint64
+	for _, s := range p.servers {
+		if s.State == ServerHealthy && s.BlockchainHeight > maxHeight {
+			maxHeight = s.BlockchainHeight
+		}
+	}
+
+	var best *poolServer
+	var bestScore float64
+
+	for _, lagging := range []bool{false, true} {
+		for _, s := range p.servers {
+			if s.State != ServerHealthy || s.URL == last {
+				continue
+			}
+
+			if !lagging && s.BlockchainHeight+poolMaxHeightLag < maxHeight {
+				continue
+			}
+
+			// Servers we haven't heard from yet score 0 so they're tried
+			score := float64(s.Latency) * (1 + 4*s.ErrorRate)
+			if best == nil || score < bestScore {
+				best = s
+				bestScore = score
+			}
+		}
+
+		if best != nil {
+			return best.URL
+		}
+	}
+
+	// Every other server is ejected or being probed, so fall back to
+	// 'last' if it's healthy, or the server that was ejected first.
+	for _, s := range p.servers {
+		if s.URL == last && s.State == ServerHealthy {
+			return s.URL
+		}
+
+		if best == nil || s.ejected.Before(best.ejected) {
+			best = s
+		}
+	}
+
+	return best.URL
+}
+
+// failover returns the URL of the healthiest server that isn't in
+// 'tried' (the servers a call already failed on), or "" if there isn't one.
+func (p *serverPool) failover(tried map[string]bool) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best *poolServer
+	var bestScore float64
+
+	for _, s := range p.servers {
+		if s.State != ServerHealthy || tried[s.URL] {
+			continue
+		}
+
+		score := float64(s.Latency) * (1 + 4*s.ErrorRate)
+		if best == nil || score < bestScore {
+			best = s
+			bestScore = score
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	return best.URL
+}
+
+// done records the outcome of a request sent to the server at 'url'.
+func (p *serverPool) done(url string, latency time.Duration, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := p.server(url)
+	if s == nil {
+		return
+	}
+
+	sample := 0.0
+	if !ok {
+		sample = 1
+	}
+
+	if s.Latency == 0 {
+		s.Latency = latency
+	} else {
+		s.Latency = time.Duration(poolDecay*float64(latency) + (1-poolDecay)*float64(s.Latency))
+	}
+
+	s.ErrorRate = poolDecay*sample + (1-poolDecay)*s.ErrorRate
+
+	if ok {
+		s.failures = 0
+		s.State = ServerHealthy
+
+		return
+	}
+
+	s.failures++
+
+	if s.State == ServerProbing || s.failures >= poolFailureThreshold {
+		s.State = ServerEjected
+		s.ejected = p.now()
+	}
+}
+
+// reportHeight records the BlockchainHeight reported by the server at 'url'.
+func (p *serverPool) reportHeight(url string, height uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := p.server(url)
+	if s != nil && height != 0 {
+		s.BlockchainHeight = height
+	}
+}
+
+func (p *serverPool) status() []ServerStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	statuses := make([]ServerStatus, len(p.servers))
+	for i, s := range p.servers {
+		statuses[i] = s.ServerStatus
+	}
+
+	return statuses
+}
+
+func (p *serverPool) server(url string) *poolServer {
+	for _, s := range p.servers {
+		if s.URL == url {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// Servers returns the status of each of our client's servers.
+func (c *Client) Servers() []ServerStatus {
+	if c.pool == nil {
+		return []ServerStatus{{URL: c.serverURL}}
+	}
+
+	return c.pool.status()
+}
+
+// CheckServers sends a request to /get_address_info on each of
+// our client's servers to update their health in our pool, then
+// returns their status. Ejected servers that respond successfully
+// are sent requests again.
+func (c *Client) CheckServers(ctx context.Context) []ServerStatus {
+	if c.pool == nil {
+		return c.Servers()
+	}
+
+	var wg sync.WaitGroup
+
+	for _, s := range c.pool.status() {
+		wg.Add(1)
+
+		go func(server string) {
+			defer wg.Done()
+
+			_ = c.do(ctx, &Call{
+				Endpoint: EndpointGetAddressInfo,
+				Request: &StandardRequest{
+					Address: c.address,
+					ViewKey: c.viewKey,
+				},
+				Response:  &GetAddressInfoResponse{},
+				encodeErr: ErrorStandardRequestEncode,
+				server:    server,
+			})
+		}(s.URL)
+	}
+
+	wg.Wait()
+
+	return c.pool.status()
+}
+
+// heightReporter is implemented by responses carrying the
+// server's BlockchainHeight, which our pool keeps track of.
+type heightReporter interface {
+	blockchainHeight() uint64
+}
+
+func (r *GetAddressInfoResponse) blockchainHeight() uint64 { return r.BlockchainHeight }
+func (r *GetAddressTxsResponse) blockchainHeight() uint64  { return r.BlockchainHeight }
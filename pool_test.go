@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPoolFailover(t *testing.T) {
+	downTries := 0
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downTries++
+
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(GetAddressInfoResponse{BlockchainHeight: 3222370})
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}))
+	defer up.Close()
+
+	client, err := NewClient(Config{
+		Address:    "xmr_address",
+		RetryCount: 1,
+		ServerURLs: []string{down.URL, up.URL},
+		ViewKey:    "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		_, err = client.GetAddressInfo()
+		if err != nil {
+			t.Fatal("GetAddressInfo() returned the error: ", err)
+		}
+	}
+
+	if downTries > poolFailureThreshold {
+		t.Errorf("the failing server was sent %d requests", downTries)
+	}
+
+	servers := client.Servers()
+
+	if servers[0].ErrorRate == 0 {
+		t.Error("the failing server's errors weren't recorded")
+	}
+
+	if servers[1].State != ServerHealthy || servers[1].BlockchainHeight != 3222370 {
+		t.Errorf("the working server's status was %+v", servers[1])
+	}
+}
+
+func TestPoolFailoverWithoutRetries(t *testing.T) {
+	downTries := 0
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downTries++
+
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer up.Close()
+
+	newClient := func() *Client {
+		client, err := NewClient(Config{
+			Address:    "xmr_address",
+			ServerURLs: []string{down.URL, up.URL},
+			ViewKey:    "xmr_view_key",
+		})
+		if err != nil {
+			t.Fatal("NewClient() returned the error: ", err)
+		}
+
+		return client
+	}
+
+	// Idempotent calls fail over to the next server, even though RetryCount is 0
+	_, err := newClient().GetAddressInfo()
+	if err != nil || downTries != 1 {
+		t.Fatalf("GetAddressInfo() returned the error %v after %d requests to the failing server", err, downTries)
+	}
+
+	// Others might have reached the server, so they aren't sent again
+	_, err = newClient().SubmitRawTx(&SubmitRawTxRequest{})
+	if !errors.Is(err, ErrorStatusCodeNotOK) || downTries != 2 {
+		t.Fatalf("SubmitRawTx() returned the error %v after %d requests to the failing server", err, downTries)
+	}
+}
+
+func TestPoolProbesEjectedServers(t *testing.T) {
+	now := time.Now()
+
+	p := newServerPool([]string{"a", "b"})
+	p.now = func() time.Time { return now }
+
+	for i := 0; i < poolFailureThreshold; i++ {
+		p.done("a", time.Millisecond, false)
+	}
+
+	for i := 0; i < 5; i++ {
+		if p.pick("") != "b" {
+			t.Fatal("pick() chose an ejected server")
+		}
+	}
+
+	now = now.Add(poolCooldown)
+
+	if p.pick("") != "a" {
+		t.Fatal("pick() didn't probe the ejected server after its cooldown")
+	}
+
+	if p.pick("") != "b" {
+		t.Fatal("pick() probed the ejected server more than once")
+	}
+
+	p.done("a", time.Millisecond, true)
+
+	if p.status()[0].State != ServerHealthy {
+		t.Error("a successful probe didn't bring the server back")
+	}
+}
+
+func TestPoolAvoidsLaggingServers(t *testing.T) {
+	p := newServerPool([]string{"fast", "slow"})
+
+	p.done("fast", time.Millisecond, true)
+	p.done("slow", time.Second, true)
+
+	if p.pick("") != "fast" {
+		t.Fatal("pick() didn't choose the fastest server")
+	}
+
+	p.reportHeight("fast", 3222000)
+	p.reportHeight("slow", 3222370)
+
+	if p.pick("") != "slow" {
+		t.Fatal("pick() chose a server that's behind the chain tip")
+	}
+
+	if p.pick("slow") != "fast" {
+		t.Fatal("pick() didn't fail over from the server that just failed")
+	}
+}
+
+func TestCheckServers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(GetAddressInfoResponse{BlockchainHeight: 7})
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(Config{
+		Address:    "xmr_address",
+		ServerURLs: []string{ts.URL, ts.URL + "/other"},
+		ViewKey:    "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	for _, s := range client.CheckServers(context.Background()) {
+		if s.State != ServerHealthy || s.BlockchainHeight != 7 || s.Latency == 0 {
+			t.Errorf("CheckServers() returned the status %+v", s)
+		}
+	}
+}
+
+func TestPoolEmptyServerURLs(t *testing.T) {
+	client, err := NewClient(Config{
+		Address:    "xmr_address",
+		ServerURLs: []string{"", ""},
+		ViewKey:    "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	if servers := client.Servers(); len(servers) != 1 || servers[0].URL != "https://api.mymonero.com" {
+		t.Errorf("a client without server URLs had the servers %+v", servers)
+	}
+}
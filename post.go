@@ -0,0 +1,327 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// post encodes 'request' (as JSON, or in our client's Encoding), posts it to 'endpoint' on our
+// light wallet server and decodes the server's reply into 'response'.
+// Failed attempts are retried as our client's RetryPolicy sees fit.
+//
+// Errors are returned as an *APIError wrapping encodeErr if 'request'
+// couldn't be encoded, ctx.Err() if 'ctx' is done, or one of our
+// standard errors (eg. ErrorStatusCodeNotOK, or ErrorAccountPendingApproval
+// if our account is waiting to be approved) otherwise.
+func (c *Client) post(ctx context.Context, endpoint Endpoint, request interface{}, response interface{}, encodeErr error) error {
+	err := c.do(ctx, &Call{
+		Endpoint:  endpoint,
+		Request:   request,
+		Response:  response,
+		encodeErr: encodeErr,
+	})
+
+	return c.trackAccount(endpoint, err)
+}
+
+// do makes call 'cl' through our client's interceptors, see post() for details.
+func (c *Client) do(ctx context.Context, cl *Call) error {
+	cl.Address = c.address
+
+	if cl.Header == nil {
+		cl.Header = http.Header{}
+	}
+
+	return chain(c.interceptors, c.send)(ctx, cl)
+}
+
+// send makes call 'cl', retrying it as our client's RetryPolicy sees fit.
+func (c *Client) send(ctx context.Context, cl *Call) error {
+	log := c.log().With(slog.String("endpoint", string(cl.Endpoint)))
+
+	apiErr := &APIError{Endpoint: cl.Endpoint}
+
+	fail := func(err error, cause error) error {
+		apiErr.Err = err
+		apiErr.Cause = cause
+		apiErr.Attempts = cl.Attempts
+		apiErr.ServerURL = cl.ServerURL
+		apiErr.StatusCode = cl.StatusCode
+
+		return apiErr
+	}
+
+	body, contentType, err := encodeRequest(cl.Request, c.binary() || cl.binary)
+	if err != nil {
+		log.Error("failed to encode request", slog.Any("error", err))
+
+		return fail(cl.encodeErr, err)
+	}
+
+	policy := c.retryPolicy
+	if cl.noRetry {
+		policy = &BackoffRetryPolicy{}
+	} else if policy == nil {
+		policy = &BackoffRetryPolicy{MaxRetries: c.retryCount, BaseDelay: c.retryTime}
+	}
+
+	tried := map[string]bool{} // The servers we've sent requests to
+	next := ""                 // The server to fail over to, if we're failing over
+	fellBack := false          // Whether a binary request failed, and we're sending it as JSON
+
+	for {
+		cl.Attempts++
+
+		server := cl.server
+		if server == "" && next != "" {
+			server, next = next, ""
+		} else if server == "" {
+			server = c.pickServer(cl.ServerURL)
+		}
+
+		tried[server] = true
+
+		cl.ServerURL = server
+		cl.StatusCode = 0
+		cl.mediaType = ""
+
+		url, err := url.JoinPath(server, string(cl.Endpoint))
+		if err != nil {
+			log.Error("failed to join server URL with endpoint", slog.String("server", server), slog.Any("error", err))
+
+			return fail(ErrorJoinPathFailed, err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			log.Error("failed to create request", slog.String("url", url), slog.Any("error", err))
+
+			return fail(ErrorPostRequestFailed, err)
+		}
+
+		for key, values := range cl.Header {
+			req.Header[key] = values
+		}
+
+		req.Header.Set("Content-Type", contentType)
+
+		if contentType == contentTypeEpee {
+			req.Header.Set("Accept", contentTypeEpee+", "+contentTypeJSON)
+		}
+
+		if c.rateLimiter != nil {
+			err = c.rateLimiter.Wait(ctx, cl.Endpoint)
+			if err != nil {
+				return fail(err, nil)
+			}
+		}
+
+		log.Debug("posting request", slog.Int("attempt", cl.Attempts), slog.String("url", url))
+
+		start := time.Now()
+
+		resp, err := c.httpClient(server).Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fail(ctx.Err(), nil)
+			}
+
+			c.serverDone(server, time.Since(start), false)
+
+			apiErr.Body = ""
+
+			wait, retry := policy.Retry(cl.Endpoint, cl.Attempts, nil, err)
+			if !retry {
+				next = c.failover(cl, tried)
+				if next != "" {
+					log.Warn("failed to post request, failing over", slog.Int("attempt", cl.Attempts), slog.String("url", url), slog.String("next", next), slog.Any("error", err))
+
+					continue
+				}
+
+				log.Error("failed to post request", slog.Int("attempt", cl.Attempts), slog.String("url", url), jsonAttr("request", body), slog.Any("error", err))
+
+				return fail(ErrorPostRequestFailed, err)
+			}
+
+			log.Warn("failed to post request, retrying", slog.Int("attempt", cl.Attempts), slog.String("url", url), slog.Duration("wait", wait), slog.Any("error", err))
+
+			err = sleep(ctx, wait)
+			if err != nil {
+				return fail(err, nil)
+			}
+
+			continue
+		}
+
+		cl.StatusCode = resp.StatusCode
+		cl.mediaType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+		if c.rateLimiter != nil {
+			c.rateLimiter.observe(resp.StatusCode)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr.Body = readErrorBody(resp.Body)
+			_ = resp.Body.Close()
+
+			// Servers that don't speak epee get the request again as JSON. Few
+			// say so with HTTP 415, so other errors are retried as JSON too,
+			// and only rule binary out if the JSON request then succeeds.
+			if contentType == contentTypeEpee && !cl.binary && binaryFallback(resp.StatusCode) {
+				if resp.StatusCode == http.StatusUnsupportedMediaType {
+					c.binaryRejected.Store(true)
+				} else {
+					fellBack = true
+				}
+
+				body, contentType, err = encodeRequest(cl.Request, false)
+				if err != nil {
+					return fail(cl.encodeErr, err)
+				}
+
+				log.Warn("server rejected a binary request, falling back to JSON", slog.String("url", url))
+
+				continue
+			}
+
+			// Client errors (eg. HTTP 400 or 403) don't count against the server's health
+			c.serverDone(server, time.Since(start), resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests)
+
+			wait, retry := policy.Retry(cl.Endpoint, cl.Attempts, resp, nil)
+			if !retry && resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented {
+				next = c.failover(cl, tried)
+				if next != "" {
+					log.Warn("server responded with a non-OK status code, failing over", slog.Int("attempt", cl.Attempts), slog.String("url", url), slog.Int("status", resp.StatusCode), slog.String("next", next))
+
+					continue
+				}
+			}
+
+			if !retry {
+				log.Error("server responded with a non-OK status code", slog.Int("attempt", cl.Attempts), slog.String("url", url), slog.Int("status", resp.StatusCode), slog.String("body", apiErr.Body))
+
+				switch {
+				case c.pendingApproval(cl, resp):
+					return fail(errAccountPendingApproval, nil)
+				case resp.StatusCode == http.StatusServiceUnavailable:
+					return fail(ErrorServiceUnavailable, nil)
+				case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented:
+					return fail(ErrorUnsupported, nil)
+				}
+
+				return fail(ErrorStatusCodeNotOK, nil)
+			}
+
+			log.Warn("server responded with a non-OK status code, retrying", slog.Int("attempt", cl.Attempts), slog.String("url", url), slog.Int("status", resp.StatusCode), slog.Duration("wait", wait))
+
+			err = sleep(ctx, wait)
+			if err != nil {
+				return fail(err, nil)
+			}
+
+			continue
+		}
+
+		err = c.decodeResponse(resp, cl, log.With(slog.String("url", url)))
+		_ = resp.Body.Close()
+
+		var decodeErr *DecodeError
+
+		if errors.As(err, &decodeErr) {
+			if ctx.Err() != nil {
+				return fail(ctx.Err(), nil)
+			}
+
+			c.serverDone(server, time.Since(start), false)
+
+			return fail(ErrorResponseUnmarshalFailed, decodeErr)
+		} else if err != nil {
+			// The caller stopped a streamed response early
+			c.serverDone(server, time.Since(start), true)
+
+			return err
+		}
+
+		c.serverDone(server, time.Since(start), true)
+
+		if fellBack {
+			c.binaryRejected.Store(true)
+		}
+
+		if r, ok := cl.Response.(heightReporter); ok && c.pool != nil {
+			c.pool.reportHeight(server, r.blockchainHeight())
+		}
+
+		log.Debug("decoded response", slog.Int("attempt", cl.Attempts), slog.String("url", url))
+
+		return nil
+	}
+}
+
+// binaryFallback reports whether a binary request answered with 'status'
+// is sent again as JSON. Servers asking us to slow down (HTTP 429 or 503)
+// may well speak epee, so those requests are retried as they are.
+func binaryFallback(status int) bool {
+	return status >= 400 && status != http.StatusTooManyRequests && status != http.StatusServiceUnavailable
+}
+
+// failover returns the server to send call 'cl' to after our RetryPolicy
+// gave up on it, or "" if there isn't one. Idempotent calls fail over
+// once to each healthy server in our pool they haven't been sent to.
+func (c *Client) failover(cl *Call, tried map[string]bool) string {
+	if c.pool == nil || cl.server != "" || !cl.Endpoint.Idempotent() {
+		return ""
+	}
+
+	return c.pool.failover(tried)
+}
+
+// pickServer returns the server our next request should be sent to,
+// avoiding 'last' (the server our last attempt failed on) if we can.
+func (c *Client) pickServer(last string) string {
+	if c.pool == nil {
+		return c.serverURL
+	}
+
+	return c.pool.pick(last)
+}
+
+// httpClient returns the *http.Client to send requests to 'server' with.
+func (c *Client) httpClient(server string) *http.Client {
+	if client, ok := c.serverClients[server]; ok {
+		return client
+	}
+
+	return c.client
+}
+
+// serverDone records the outcome of a request to 'server' in our pool.
+func (c *Client) serverDone(server string, latency time.Duration, ok bool) {
+	if c.pool != nil {
+		c.pool.done(server, latency, ok)
+	}
+}
+
+// sleep waits for 'd' to pass, returning
+// early with ctx.Err() if 'ctx' is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
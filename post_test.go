@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPostContextCanceledWhileRetrying(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:    "xmr_address",
+		client:     &http.Client{},
+		retryCount: 1,
+		retryTime:  time.Hour,
+		serverURL:  ts.URL,
+		viewKey:    "xmr_view_key",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	_, err := client.GetAddressInfoContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("GetAddressInfoContext() returned the error: ", err)
+	}
+
+	if time.Since(start) > 10*time.Second {
+		t.Error("GetAddressInfoContext() kept waiting after its context expired")
+	}
+}
+
+func TestPostContextCanceledDuringRequest(t *testing.T) {
+	release := make(chan struct{})
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+	defer close(release)
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := client.LoginContext(ctx, &LoginRequest{})
+	if !errors.Is(err, context.Canceled) {
+		t.Error("LoginContext() returned the error: ", err)
+	}
+}
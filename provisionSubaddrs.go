@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"errors"
+)
+
+// ProvisionSubaddrsRequest asks the server to register new
+// subaddresses for us in a call to ProvisionSubaddrs().
+//
+// NumMajor major indexes (starting at MajorIndex), with NumMinor
+// minor indexes each (starting at MinorIndex), are registered.
+// It is not required to pass Address or ViewKey, as those are
+// derived from 'client'.
+type ProvisionSubaddrsRequest struct {
+	Address    string `json:"address"`
+	ViewKey    string `json:"view_key" epee:"hex"` // hex encoded binary
+	MajorIndex uint32 `json:"maj_i"`
+	MinorIndex uint32 `json:"min_i"`
+	NumMajor   uint32 `json:"n_maj"`
+	NumMinor   uint32 `json:"n_min"`
+	GetAll     bool   `json:"get_all"`
+}
+
+// ProvisionSubaddrsResponse is the response from a call to ProvisionSubaddrs().
+//
+// NewSubaddrs holds the ranges that were registered by our
+// call. AllSubaddrs is only sent if GetAll was set.
+type ProvisionSubaddrsResponse struct {
+	NewSubaddrs []Subaddrs `json:"new_subaddrs"`
+	AllSubaddrs []Subaddrs `json:"all_subaddrs"`
+}
+
+var ErrorProvisionSubaddrsRequestEncode = errors.New("failed to encode ProvisionSubaddrsRequest using data from 'client' and 'request'")
+
+// ProvisionSubaddrs asks the server to register
+// a block of new subaddresses for our account.
+//
+// It isn't idempotent, so it's not retried
+// unless our RetryPolicy says otherwise.
+func (c *Client) ProvisionSubaddrs(request *ProvisionSubaddrsRequest) (*ProvisionSubaddrsResponse, error) {
+	return c.ProvisionSubaddrsContext(context.Background(), request)
+}
+
+// ProvisionSubaddrsContext is like ProvisionSubaddrs but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (c *Client) ProvisionSubaddrsContext(ctx context.Context, request *ProvisionSubaddrsRequest) (*ProvisionSubaddrsResponse, error) {
+	const path = EndpointProvisionSubaddrs
+
+	request.Address = c.address
+	request.ViewKey = c.viewKey
+
+	var response = &ProvisionSubaddrsResponse{}
+
+	err := c.post(ctx, path, request, response, ErrorProvisionSubaddrsRequestEncode)
+	if err != nil {
+		return &ProvisionSubaddrsResponse{}, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestProvisionSubaddrs(t *testing.T) {
+	request := &ProvisionSubaddrsRequest{
+		Address:    "xmr_address",
+		ViewKey:    "xmr_view_key",
+		MajorIndex: 0,
+		MinorIndex: 100,
+		NumMajor:   1,
+		NumMinor:   50,
+	}
+
+	response := ProvisionSubaddrsResponse{
+		NewSubaddrs: []Subaddrs{{Major: 0, Ranges: []IndexRange{{100, 149}}}},
+	}
+
+	tries := 0
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		tries++
+
+		var req = &ProvisionSubaddrsRequest{}
+
+		err := json.NewDecoder(r.Body).Decode(req)
+		if err != nil {
+			t.Error("ProvisionSubaddrs() made an invalid request: ", err)
+		}
+
+		if !reflect.DeepEqual(req, request) {
+			t.Error("req struct didn't match the original data in request")
+		}
+
+		if tries == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:    request.Address,
+		client:     &http.Client{},
+		retryCount: 1,
+		serverURL:  ts.URL,
+		viewKey:    request.ViewKey,
+	}
+
+	// Provisioning isn't idempotent, so it isn't retried
+	_, err := client.ProvisionSubaddrs(&ProvisionSubaddrsRequest{MinorIndex: 100, NumMajor: 1, NumMinor: 50})
+	if !errors.Is(err, ErrorServiceUnavailable) || tries != 1 {
+		t.Fatalf("ProvisionSubaddrs() was sent %d time(s) and returned the error: %v", tries, err)
+	}
+
+	resp, err := client.ProvisionSubaddrs(&ProvisionSubaddrsRequest{MinorIndex: 100, NumMajor: 1, NumMinor: 50})
+	if err != nil {
+		t.Fatal("ProvisionSubaddrs() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(*resp, response) {
+		t.Error("response struct didn't match the original data")
+	}
+}
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// rateLimiterBackoff is what a RateLimiter's rate is multiplied
+	// by when the server tells us to slow down (HTTP 429 or 503).
+	rateLimiterBackoff = 0.5
+
+	// rateLimiterRecovery is the fraction of a RateLimiter's
+	// configured rate given back after each successful request.
+	rateLimiterRecovery = 0.05
+
+	// rateLimiterMinFraction is the lowest fraction of its
+	// configured rate a RateLimiter will slow down to.
+	rateLimiterMinFraction = 0.01
+)
+
+// RateLimiter is a token bucket that limits how fast requests are
+// sent. A single RateLimiter can be shared by any number of clients
+// (see Config.RateLimiter), eg. every client talking to the same server.
+//
+// The limiter adapts to the server: when it responds with HTTP 429 or
+// 503 the rate is halved, then it recovers a little with every
+// successful request until it's back to the configured rate.
+type RateLimiter struct {
+	mu      sync.Mutex
+	limit   float64              // The configured rate, in tokens per second
+	rate    float64              // The current rate, in tokens per second
+	burst   float64              // The size of the bucket
+	tokens  float64              // Tokens left in the bucket. Negative if requests are waiting on it.
+	last    time.Time            // When tokens was last refilled
+	slowed  time.Time            // When the rate was last lowered
+	weights map[Endpoint]float64 // The number of tokens a request to each endpoint costs
+	now     func() time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing 'rate' requests per
+// second, with bursts of up to 'burst' requests. Rates of 0 or less
+// (which would never refill the bucket) are raised to 1 request per
+// second, like bursts below 1 are raised to 1 request.
+//
+// Requests to an endpoint cost the tokens in 'weights' (eg. 5 for
+// EndpointGetAddressTxs), or 1 token if the endpoint isn't in it.
+func NewRateLimiter(rate float64, burst int, weights map[Endpoint]float64) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	if !(rate > 0) { // Also catches NaN
+		rate = 1
+	}
+
+	l := &RateLimiter{
+		limit:   rate,
+		rate:    rate,
+		burst:   float64(burst),
+		tokens:  float64(burst),
+		weights: map[Endpoint]float64{},
+		now:     time.Now,
+	}
+
+	for endpoint, weight := range weights {
+		l.weights[endpoint] = weight
+	}
+
+	l.last = l.now()
+
+	return l
+}
+
+// Wait blocks until a request to 'endpoint' can be sent,
+// returning early with ctx.Err() if 'ctx' is done first.
+func (l *RateLimiter) Wait(ctx context.Context, endpoint Endpoint) error {
+	cost := l.weight(endpoint)
+
+	l.mu.Lock()
+
+	l.refill()
+
+	// Take our tokens now, even if that puts the bucket in debt, so
+	// waiting requests are let through in the order they arrived.
+	l.tokens -= cost
+
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+
+	err := sleep(ctx, wait)
+	if err != nil {
+		l.mu.Lock()
+		l.tokens += cost
+		l.mu.Unlock()
+	}
+
+	return err
+}
+
+// Rate returns the number of tokens per second the limiter
+// currently allows, which is lowered while the server is throttling us.
+func (l *RateLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rate
+}
+
+// observe adapts the limiter's rate to a response with status code 'status'.
+func (l *RateLimiter) observe(status int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+
+	now := l.now()
+
+	switch {
+	case status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable:
+		// Requests sent before we slowed down are likely to be throttled
+		// too, so wait for a request at our new rate before slowing again.
+		if now.Sub(l.slowed) < time.Duration(float64(time.Second)/l.rate) {
+			return
+		}
+
+		l.slowed = now
+		l.rate *= rateLimiterBackoff
+
+		if floor := l.limit * rateLimiterMinFraction; l.rate < floor {
+			l.rate = floor
+		}
+	case status < 400:
+		l.rate += l.limit * rateLimiterRecovery
+
+		if l.rate > l.limit {
+			l.rate = l.limit
+		}
+	}
+}
+
+// refill adds the tokens earned since the last refill to the bucket.
+// l.mu must be held.
+func (l *RateLimiter) refill() {
+	now := l.now()
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+
+	l.last = now
+}
+
+func (l *RateLimiter) weight(endpoint Endpoint) float64 {
+	if weight, ok := l.weights[endpoint]; ok {
+		return weight
+	}
+
+	return 1
+}
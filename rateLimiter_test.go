@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWeights(t *testing.T) {
+	now := time.Unix(0, 0)
+
+	l := NewRateLimiter(10, 10, map[Endpoint]float64{EndpointGetAddressTxs: 5})
+	l.now = func() time.Time { return now }
+	l.last = now
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Two heavy requests drain the bucket, so the next one has to wait
+	for i := 0; i < 2; i++ {
+		err := l.Wait(ctx, EndpointGetAddressTxs)
+		if err != nil {
+			t.Fatal("Wait() returned the error: ", err)
+		}
+	}
+
+	err := l.Wait(ctx, EndpointLogin)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal("Wait() didn't wait for an empty bucket, it returned: ", err)
+	}
+
+	// A canceled wait gives its tokens back, and a tenth
+	// of a second refills enough for a light request
+	now = now.Add(100 * time.Millisecond)
+
+	err = l.Wait(ctx, EndpointLogin)
+	if err != nil {
+		t.Fatal("Wait() returned the error: ", err)
+	}
+}
+
+func TestRateLimiterAdapts(t *testing.T) {
+	now := time.Unix(0, 0)
+
+	l := NewRateLimiter(10, 1, nil)
+	l.now = func() time.Time { return now }
+	l.last = now
+
+	l.observe(http.StatusTooManyRequests)
+	l.observe(http.StatusServiceUnavailable) // Sent before we slowed down
+
+	if l.Rate() != 5 {
+		t.Fatal("the rate wasn't halved after the server throttled us: ", l.Rate())
+	}
+
+	now = now.Add(time.Second)
+
+	l.observe(http.StatusServiceUnavailable)
+
+	if l.Rate() != 2.5 {
+		t.Fatal("the rate wasn't halved again: ", l.Rate())
+	}
+
+	for i := 0; i < 100; i++ {
+		l.observe(http.StatusOK)
+	}
+
+	if l.Rate() != 10 {
+		t.Error("the rate didn't recover to its limit: ", l.Rate())
+	}
+}
+
+func TestRateLimiterInvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -1, math.NaN()} {
+		l := NewRateLimiter(rate, 1, nil)
+
+		if l.Rate() != 1 {
+			t.Errorf("NewRateLimiter(%v) had the rate %v", rate, l.Rate())
+		}
+
+		now := time.Unix(0, 0)
+
+		l.now = func() time.Time { return now }
+		l.last = now
+
+		// The bucket refills, so the second request only waits a second
+		for i := 0; i < 2; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+
+			err := l.Wait(ctx, EndpointGetAddressInfo)
+			if err != nil {
+				t.Fatalf("Wait() returned the error %v with the rate %v", err, rate)
+			}
+
+			cancel()
+
+			now = now.Add(time.Second)
+		}
+	}
+}
+
+func TestRateLimiterShared(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		throttle := requests == 1
+		mu.Unlock()
+
+		if throttle {
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		err := json.NewEncoder(w).Encode(GetAddressInfoResponse{})
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}))
+	defer ts.Close()
+
+	limiter := NewRateLimiter(1000, 1, nil)
+
+	var clients []*Client
+
+	for _, address := range []string{"xmr_address_1", "xmr_address_2"} {
+		client, err := NewClient(Config{
+			Address:     address,
+			RateLimiter: limiter,
+			RetryCount:  1,
+			ServerURL:   ts.URL,
+			ViewKey:     "xmr_view_key",
+		})
+		if err != nil {
+			t.Fatal("NewClient() returned the error: ", err)
+		}
+
+		clients = append(clients, client)
+	}
+
+	_, err := clients[0].GetAddressInfo()
+	if err != nil {
+		t.Fatal("GetAddressInfo() returned the error: ", err)
+	}
+
+	if limiter.Rate() >= 1000 {
+		t.Error("the shared limiter didn't slow down after the server throttled us")
+	}
+
+	_, err = clients[1].GetAddressInfo()
+	if err != nil {
+		t.Fatal("GetAddressInfo() returned the error: ", err)
+	}
+
+	if limiter.Rate() <= 500 {
+		t.Error("the shared limiter didn't recover after another client's request succeeded: ", limiter.Rate())
+	}
+}
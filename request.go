@@ -12,28 +12,39 @@ import (
 // be passed to function calls after client creation.
 type StandardRequest struct {
 	Address string `json:"address"`
-	ViewKey string `json:"view_key"`
+	ViewKey string `json:"view_key" epee:"hex"`
 }
 
 type Transaction struct {
 	ID            uint64    `json:"id"`
-	Hash          string    `json:"hash"` // hex encoded binary
+	Hash          string    `json:"hash" epee:"hex"` // hex encoded binary
 	Timestamp     time.Time `json:"timestamp"`
-	TotalReceived string    `json:"total_received"`
-	TotalSent     string    `json:"total_sent"`
+	TotalReceived string    `json:"total_received" epee:"uint64"`
+	TotalSent     string    `json:"total_sent" epee:"uint64"`
 	UnlockTime    uint64    `json:"unlock_time"`
 	Height        uint64    `json:"height"`
 	SpentOutputs  []Spend   `json:"spent_outputs"`
-	PaymentID     string    `json:"payment_id"` // hex encoded binary
+	PaymentID     string    `json:"payment_id" epee:"hex"` // hex encoded binary
 	Coinbase      bool      `json:"coinbase"`
 	Mempool       bool      `json:"mempool"`
 	Mixin         uint64    `json:"mixin"`
 }
 
 type Spend struct {
-	Amount      string `json:"amount"`
-	KeyImage    string `json:"key_image"`  // hex encoded binary
-	TxPublicKey string `json:"tx_pub_key"` // hex encoded binary
+	Amount      string `json:"amount" epee:"uint64"`
+	KeyImage    string `json:"key_image" epee:"hex"`  // hex encoded binary
+	TxPublicKey string `json:"tx_pub_key" epee:"hex"` // hex encoded binary
 	OutIndex    uint16 `json:"out_index"`
 	Mixin       uint32 `json:"mixin"`
 }
+
+// IndexRange is an inclusive range of subaddress minor
+// indexes, from IndexRange[0] to IndexRange[1].
+type IndexRange [2]uint32
+
+// Subaddrs holds the ranges of minor indexes
+// registered under a major (account) index.
+type Subaddrs struct {
+	Major  uint32       `json:"key"`
+	Ranges []IndexRange `json:"value"`
+}
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides whether a failed call to an
+// endpoint should be retried, and how long to wait first.
+type RetryPolicy interface {
+	// Retry is called after every failed attempt at calling 'endpoint'.
+	//
+	// 'attempt' is the number of attempts made so far, starting at 1.
+	// 'resp' is the server's response, or nil if the request failed
+	// with the transport error 'err'. Only resp.StatusCode and
+	// resp.Header should be used, as the body may already be closed.
+	Retry(endpoint Endpoint, attempt int, resp *http.Response, err error) (wait time.Duration, retry bool)
+}
+
+// BackoffRetryPolicy is the default RetryPolicy.
+//
+// It retries transport errors and HTTP 429, 502, 503 and 504 responses
+// with an exponential backoff and jitter, honoring the server's
+// Retry-After header when there is one. Endpoints that aren't
+// idempotent are only retried if RetryNonIdempotent is set.
+type BackoffRetryPolicy struct {
+	MaxRetries         int           // The number of times to retry a call before giving up
+	BaseDelay          time.Duration // The time to wait before the first retry. It's doubled for each subsequent retry.
+	MaxDelay           time.Duration // The longest time to wait in between retries. Defaults to 30 seconds.
+	RetryNonIdempotent bool          // Retry endpoints like /submit_raw_tx, which may have taken effect
+}
+
+const defaultMaxRetryDelay = 30 * time.Second
+
+// Retry implements RetryPolicy
+func (p *BackoffRetryPolicy) Retry(endpoint Endpoint, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt > p.MaxRetries {
+		return 0, false
+	}
+
+	if !endpoint.Idempotent() && !p.RetryNonIdempotent {
+		return 0, false
+	}
+
+	if resp != nil && !retryableStatus(resp.StatusCode) {
+		return 0, false
+	}
+
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxRetryDelay
+	}
+
+	if resp != nil {
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if ok {
+			if wait > maxDelay {
+				wait = maxDelay
+			}
+
+			return wait, true
+		}
+	}
+
+	wait := p.BaseDelay
+	for i := 1; i < attempt && wait < maxDelay; i++ {
+		wait *= 2
+	}
+
+	if wait > maxDelay {
+		wait = maxDelay
+	}
+
+	// Wait somewhere in between half and all of our
+	// delay so many clients don't retry in lockstep.
+	if wait > 1 {
+		wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+	}
+
+	return wait, true
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// retryAfter parses the value of a Retry-After header,
+// which is either a number of seconds or an HTTP date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseUint(value, 10, 32)
+	if err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	wait := date.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+
+	return wait, true
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBackoffRetryPolicy(t *testing.T) {
+	policy := &BackoffRetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  time.Second,
+		MaxDelay:   3 * time.Second,
+	}
+
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		attempt  int
+		status   int // 0 for a transport error
+		header   http.Header
+		retry    bool
+		min, max time.Duration
+	}{
+		{"transport error", EndpointLogin, 1, 0, nil, true, 500 * time.Millisecond, time.Second},
+		{"too many requests", EndpointGetAddressInfo, 1, http.StatusTooManyRequests, nil, true, 500 * time.Millisecond, time.Second},
+		{"bad gateway", EndpointGetAddressTxs, 2, http.StatusBadGateway, nil, true, time.Second, 2 * time.Second},
+		{"gateway timeout", EndpointGetUnspentOuts, 3, http.StatusGatewayTimeout, nil, true, 1500 * time.Millisecond, 3 * time.Second},
+		{"service unavailable", EndpointGetRandomOuts, 1, http.StatusServiceUnavailable, nil, true, 500 * time.Millisecond, time.Second},
+		{"retry after seconds", EndpointImportRequest, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"2"}}, true, 2 * time.Second, 2 * time.Second},
+		{"retry after capped", EndpointImportRequest, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"120"}}, true, 3 * time.Second, 3 * time.Second},
+		{"too many attempts", EndpointLogin, 4, http.StatusServiceUnavailable, nil, false, 0, 0},
+		{"bad request", EndpointGetUnspentOuts, 1, http.StatusBadRequest, nil, false, 0, 0},
+		{"not idempotent", EndpointSubmitRawTx, 1, http.StatusServiceUnavailable, nil, false, 0, 0},
+	}
+
+	for _, test := range tests {
+		var resp *http.Response
+		var err error
+
+		if test.status == 0 {
+			err = errors.New("connection refused")
+		} else {
+			resp = &http.Response{StatusCode: test.status, Header: test.header}
+		}
+
+		wait, retry := policy.Retry(test.endpoint, test.attempt, resp, err)
+		if retry != test.retry {
+			t.Errorf("%s: Retry() returned retry=%v", test.name, retry)
+		}
+
+		if wait < test.min || wait > test.max {
+			t.Errorf("%s: Retry() waited %v, expected between %v and %v", test.name, wait, test.min, test.max)
+		}
+	}
+}
+
+func TestRetryCountLimitsRetries(t *testing.T) {
+	tries := 0
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		tries++
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:    "xmr_address",
+		client:     &http.Client{},
+		retryCount: 2,
+		serverURL:  ts.URL,
+		viewKey:    "xmr_view_key",
+	}
+
+	_, err := client.GetAddressInfo()
+	if !errors.Is(err, ErrorServiceUnavailable) {
+		t.Error("GetAddressInfo() returned the error: ", err)
+	}
+
+	if tries != 3 {
+		t.Errorf("GetAddressInfo() was sent %d times, expected 3", tries)
+	}
+}
@@ -3,7 +3,13 @@
 
 package gomonerolight
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
 
 // Request errors
 var ErrorJoinPathFailed = errors.New("failed to join server url with path. Is the server URL in 'client' valid?")
@@ -13,8 +19,67 @@ var ErrorPostRequestFailed = errors.New("failed to post data to endpoint")
 var ErrorStandardRequestEncode = errors.New("failed to encode standard request using data from 'client'")
 
 // Request status errors
-var ErrorServiceUnavailable = errors.New(`server responded with HTTP error "Service Unavailible" (status code 503) too many times`)
+var ErrorServiceUnavailable = errors.New("server returned HTTP 503 Service Unavailable")
 var ErrorStatusCodeNotOK = errors.New("server responded with a non-OK status code")
 
+// ErrorUnsupported is returned when the server doesn't have an endpoint
+// (HTTP 404 or 501). It's errors.ErrUnsupported, so either can be checked for.
+var ErrorUnsupported = errors.ErrUnsupported
+
 // Response errors
 var ErrorResponseUnmarshalFailed = errors.New("failed to unmarshal response body from our POST request")
+
+// maxAPIErrorBody is the most of a response body an APIError holds
+const maxAPIErrorBody = 512
+
+// APIError describes a failed call to one of our light wallet server's endpoints.
+//
+// It wraps one of the errors above (eg. ErrorStatusCodeNotOK) so
+// errors.Is() can still be used to check what kind of failure it was.
+type APIError struct {
+	Endpoint   Endpoint // The endpoint we called (eg. "/get_unspent_outs")
+	ServerURL  string   // The server our last attempt was sent to
+	StatusCode int      // The HTTP status code of the server's last response, if we got one
+	Body       string   // The server's last response body, truncated to 512 bytes
+	Attempts   int      // The number of requests made to Endpoint
+	Err        error    // One of the errors above, describing what went wrong
+	Cause      error    // The error from net/http, encoding/json, etc. that caused Err, if there was one
+}
+
+func (e *APIError) Error() string {
+	s := string(e.Endpoint) + ": " + e.Err.Error()
+
+	if e.StatusCode != 0 {
+		s += fmt.Sprintf(" (HTTP %d after %d attempt(s))", e.StatusCode, e.Attempts)
+	} else if e.Attempts > 1 {
+		s += fmt.Sprintf(" (after %d attempts)", e.Attempts)
+	}
+
+	if e.Cause != nil {
+		s += ": " + e.Cause.Error()
+	} else if e.Body != "" {
+		s += ": " + strconv.Quote(e.Body)
+	}
+
+	return s
+}
+
+// Unwrap returns Err and Cause, for use with errors.Is() and errors.As()
+func (e *APIError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+
+	return []error{e.Err, e.Cause}
+}
+
+// readErrorBody reads the start of a
+// non-OK response body for an APIError.
+func readErrorBody(r io.Reader) string {
+	b, _ := io.ReadAll(io.LimitReader(r, maxAPIErrorBody+1))
+	if len(b) > maxAPIErrorBody {
+		return strings.ToValidUTF8(string(b[:maxAPIErrorBody]), "") + "..."
+	}
+
+	return strings.ToValidUTF8(string(b), "")
+}
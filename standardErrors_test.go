@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIErrorStatusCode(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		_, err := w.Write([]byte("amount too large"))
+		if err != nil {
+			t.Error("failed to write HTTP status code 400")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:    "xmr_address",
+		client:     &http.Client{},
+		retryCount: 3,
+		serverURL:  ts.URL,
+		viewKey:    "xmr_view_key",
+	}
+
+	_, err := client.GetUnspentOuts(&GetUnspentOutsRequest{Amount: "314159"})
+	if !errors.Is(err, ErrorStatusCodeNotOK) {
+		t.Error("GetUnspentOuts() returned the error: ", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("GetUnspentOuts() didn't return an *APIError")
+	}
+
+	if apiErr.Endpoint != EndpointGetUnspentOuts {
+		t.Error("APIError.Endpoint was ", apiErr.Endpoint)
+	}
+
+	if apiErr.StatusCode != http.StatusBadRequest {
+		t.Error("APIError.StatusCode was ", apiErr.StatusCode)
+	}
+
+	if apiErr.Body != "amount too large" {
+		t.Error("APIError.Body was ", apiErr.Body)
+	}
+
+	if apiErr.Attempts != 1 {
+		t.Error("APIError.Attempts was ", apiErr.Attempts)
+	}
+}
+
+func TestAPIErrorUnsupported(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusNotImplemented} {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}
+
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		client := &Client{
+			address:    "xmr_address",
+			client:     &http.Client{},
+			retryCount: 3,
+			serverURL:  ts.URL,
+			viewKey:    "xmr_view_key",
+		}
+
+		_, err := client.GetSubaddrs()
+		if !errors.Is(err, ErrorUnsupported) || !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("GetSubaddrs() returned the error %v for HTTP %d", err, status)
+		}
+
+		ts.Close()
+	}
+}
+
+func TestAPIErrorTruncatedBody(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		_, err := w.Write([]byte(strings.Repeat("x", 4*maxAPIErrorBody)))
+		if err != nil {
+			t.Error("failed to write HTTP status code 500")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	_, err := client.GetAddressInfo()
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("GetAddressInfo() didn't return an *APIError")
+	}
+
+	if apiErr.StatusCode != http.StatusInternalServerError {
+		t.Error("APIError.StatusCode was ", apiErr.StatusCode)
+	}
+
+	if len(apiErr.Body) != maxAPIErrorBody+len("...") {
+		t.Error("APIError.Body wasn't truncated, its length was ", len(apiErr.Body))
+	}
+}
+
+func TestAPIErrorDecodeCause(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"status": 7}`))
+		if err != nil {
+			t.Error("failed to write our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	_, err := client.SubmitRawTx(&SubmitRawTxRequest{Tx: "00"})
+	if !errors.Is(err, ErrorResponseUnmarshalFailed) {
+		t.Error("SubmitRawTx() returned the error: ", err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Error("SubmitRawTx() didn't wrap the decoding error: ", err)
+	}
+}
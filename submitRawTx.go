@@ -4,20 +4,14 @@
 package gomonerolight
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
-	"fmt"
-	"net/http"
-	"net/url"
-	"os"
-	"time"
 )
 
 // SubmitRawTxRequest holds a raw (binary) Monero
 // transaction that's been encoded as an ASCII string.
 type SubmitRawTxRequest struct {
-	Tx string `json:"tx"` // hex encoded binary
+	Tx string `json:"tx" epee:"hex"` // hex encoded binary
 }
 
 // SubmitRawTxResponse holds the status of a call to
@@ -35,54 +29,19 @@ var ErrorSubmitRawTxRequestEncode = errors.New("failed to encode SubmitRawTxRequ
 // In order to call it, you must supply a request struct*
 // that has a raw transaction encoded into an ASCII string.
 func (c *Client) SubmitRawTx(request *SubmitRawTxRequest) (*SubmitRawTxResponse, error) {
-	const path = "/submit_raw_tx"
-
-	b := new(bytes.Buffer)
-
-	err := json.NewEncoder(b).Encode(request)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to encode:\n\n%#v\n\nwith error:\n%v\n\n", *request, err)
-
-		return &SubmitRawTxResponse{}, ErrorSubmitRawTxRequestEncode
-	}
-
-	url, err := url.JoinPath(c.serverURL, path)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to join:\n%s\nand\n%s\nwith error:\n\n%v\n\n", c.serverURL, path, err)
-
-		return &SubmitRawTxResponse{}, ErrorJoinPathFailed
-	}
-
-	retries := 0
-
-POST_REQUEST:
-
-	resp, err := c.client.Post(url, "application/json", bytes.NewReader(b.Bytes()))
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to post:\n\n%s\n\n to our endpoint at:\n\n%s\n\nwith error:\n\n%v\n\n", b.String(), url, err)
-
-		return &SubmitRawTxResponse{}, ErrorPostRequestFailed
-	}
-
-	if resp.StatusCode == http.StatusServiceUnavailable {
-		if retries < c.retryCount {
-			time.Sleep(c.retryTime)
+	return c.SubmitRawTxContext(context.Background(), request)
+}
 
-			goto POST_REQUEST
-		}
-
-		return &SubmitRawTxResponse{}, ErrorServiceUnavailable
-	} else if resp.StatusCode != http.StatusOK {
-		return &SubmitRawTxResponse{}, ErrorStatusCodeNotOK
-	}
+// SubmitRawTxContext is like SubmitRawTx but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (c *Client) SubmitRawTxContext(ctx context.Context, request *SubmitRawTxRequest) (*SubmitRawTxResponse, error) {
+	const path = EndpointSubmitRawTx
 
 	var response = &SubmitRawTxResponse{}
 
-	err = json.NewDecoder(resp.Body).Decode(response)
+	err := c.post(ctx, path, request, response, ErrorSubmitRawTxRequestEncode)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "failed to decode:\n\n%#v\n\nwith error:\n%v\n", response, err)
-
-		return &SubmitRawTxResponse{}, ErrorResponseUnmarshalFailed
+		return &SubmitRawTxResponse{}, err
 	}
 
 	return response, nil
@@ -6,6 +6,7 @@ package gomonerolight
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -70,8 +71,12 @@ func TestSubmitRawTx(t *testing.T) {
 		client:     &http.Client{},
 		retryCount: tryCount,
 		retryTime:  time.Duration(0),
-		serverURL:  ts.URL,
-		viewKey:    "xmr_view_key",
+		retryPolicy: &BackoffRetryPolicy{
+			MaxRetries:         tryCount,
+			RetryNonIdempotent: true, // SubmitRawTx isn't retried by default
+		},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
 	}
 
 	resp, err := client.SubmitRawTx(request)
@@ -83,3 +88,37 @@ func TestSubmitRawTx(t *testing.T) {
 		t.Error("response struct didn't match the original data")
 	}
 }
+
+func TestSubmitRawTxNotRetried(t *testing.T) {
+	tries := 0
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		tries++
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	cfg := Config{
+		Address:    "xmr_address",
+		RetryCount: 3,
+		ServerURL:  ts.URL,
+		ViewKey:    "xmr_view_key",
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	_, err = client.SubmitRawTx(&SubmitRawTxRequest{Tx: "00"})
+	if !errors.Is(err, ErrorServiceUnavailable) {
+		t.Error("SubmitRawTx() returned the error: ", err)
+	}
+
+	if tries != 1 {
+		t.Errorf("SubmitRawTx() was sent %d times, but shouldn't have been retried", tries)
+	}
+}
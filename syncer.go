@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// SyncState is what a Syncer knows about an account.
+type SyncState struct {
+	Address          string                 `json:"address"`
+	ScannedHeight    uint64                 `json:"scanned_height"`
+	BlockchainHeight uint64                 `json:"blockchain_height"`
+	Transactions     map[string]Transaction `json:"transactions"` // By hash
+}
+
+// Store persists the SyncStates of Syncers, so they can pick up where
+// they left off after a restart. See FileStore for the default Store.
+type Store interface {
+	// Load returns the state saved for 'address', or nil if there isn't one.
+	Load(ctx context.Context, address string) (*SyncState, error)
+
+	// Save saves 'state', replacing any state saved for state.Address.
+	Save(ctx context.Context, state *SyncState) error
+}
+
+// HeightChange is a transaction whose height changed (eg. after a reorg).
+type HeightChange struct {
+	Transaction Transaction
+	OldHeight   uint64
+}
+
+// SyncDiff describes how an account changed in between two refreshes.
+// Transactions are sorted by height, then hash.
+//
+// When the server rescans our account (eg. after an ImportWorkflow),
+// ScannedHeight goes backwards. Transactions in blocks it hasn't
+// rescanned yet aren't Removed, but kept until it gets to them again.
+type SyncDiff struct {
+	New              []Transaction  // Transactions we hadn't seen before
+	Confirmed        []Transaction  // Transactions that were in the mempool, and now are in a block
+	HeightChanged    []HeightChange // Transactions that moved to another block
+	Unconfirmed      []Transaction  // Transactions that were in a block, and are back in the mempool (eg. after a reorg)
+	Removed          []Transaction  // Transactions the server no longer reports (eg. dropped from the mempool)
+	ScannedHeight    uint64
+	BlockchainHeight uint64
+}
+
+// Empty reports whether no transactions changed.
+func (d *SyncDiff) Empty() bool {
+	return len(d.New) == 0 && len(d.Confirmed) == 0 && len(d.HeightChanged) == 0 && len(d.Unconfirmed) == 0 && len(d.Removed) == 0
+}
+
+// Syncer keeps track of our account's transactions across calls to
+// /get_address_txs, so callers only see what changed in between them.
+type Syncer struct {
+	client *Client
+	store  Store
+
+	mu    sync.Mutex
+	state *SyncState // Loaded from store on our first refresh
+}
+
+// NewSyncer creates a Syncer for our account, saving its state to 'store'.
+func (c *Client) NewSyncer(store Store) *Syncer {
+	return &Syncer{client: c, store: store}
+}
+
+// Refresh gets our account's transactions and returns how they changed
+// since our last refresh (or the state in our Store, after a restart).
+//
+// The new state is saved before Refresh returns, so a diff is only
+// returned once. If it can't be saved, the error is returned instead
+// and the next refresh returns the diff again.
+func (s *Syncer) Refresh(ctx context.Context) (*SyncDiff, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		state, err := s.store.Load(ctx, s.client.address)
+		if err != nil {
+			return &SyncDiff{}, err
+		}
+
+		if state == nil {
+			state = &SyncState{Address: s.client.address}
+		}
+
+		s.state = state
+	}
+
+	txs := map[string]Transaction{}
+
+	response, err := s.client.IterAddressTxs(ctx, func(tx Transaction) error {
+		txs[tx.Hash] = tx
+
+		return nil
+	})
+	if err != nil {
+		return &SyncDiff{}, err
+	}
+
+	diff := &SyncDiff{
+		ScannedHeight:    response.ScannedHeight,
+		BlockchainHeight: response.BlockchainHeight,
+	}
+
+	for hash, tx := range txs {
+		old, ok := s.state.Transactions[hash]
+
+		switch {
+		case !ok:
+			diff.New = append(diff.New, tx)
+		case old.Mempool && !tx.Mempool:
+			diff.Confirmed = append(diff.Confirmed, tx)
+		case !old.Mempool && tx.Mempool:
+			diff.Unconfirmed = append(diff.Unconfirmed, tx)
+		case !old.Mempool && !tx.Mempool && old.Height != tx.Height:
+			diff.HeightChanged = append(diff.HeightChanged, HeightChange{Transaction: tx, OldHeight: old.Height})
+		}
+	}
+
+	for hash, old := range s.state.Transactions {
+		if _, ok := txs[hash]; ok {
+			continue
+		}
+
+		// The server is rescanning, and hasn't gotten to this transaction's block yet
+		if !old.Mempool && old.Height > response.ScannedHeight {
+			txs[hash] = old
+
+			continue
+		}
+
+		diff.Removed = append(diff.Removed, old)
+	}
+
+	sortTransactions(diff.New)
+	sortTransactions(diff.Confirmed)
+	sortTransactions(diff.Unconfirmed)
+	sortTransactions(diff.Removed)
+
+	sort.Slice(diff.HeightChanged, func(i, j int) bool {
+		return transactionLess(diff.HeightChanged[i].Transaction, diff.HeightChanged[j].Transaction)
+	})
+
+	state := &SyncState{
+		Address:          s.state.Address,
+		ScannedHeight:    response.ScannedHeight,
+		BlockchainHeight: response.BlockchainHeight,
+		Transactions:     txs,
+	}
+
+	err = s.store.Save(ctx, state)
+	if err != nil {
+		return &SyncDiff{}, err
+	}
+
+	s.state = state
+
+	return diff, nil
+}
+
+// State returns a copy of the Syncer's state as of its last refresh,
+// or nil if it hasn't refreshed yet.
+func (s *Syncer) State() *SyncState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		return nil
+	}
+
+	state := *s.state
+
+	state.Transactions = make(map[string]Transaction, len(s.state.Transactions))
+	for hash, tx := range s.state.Transactions {
+		state.Transactions[hash] = tx
+	}
+
+	return &state
+}
+
+func sortTransactions(txs []Transaction) {
+	sort.Slice(txs, func(i, j int) bool {
+		return transactionLess(txs[i], txs[j])
+	})
+}
+
+func transactionLess(a, b Transaction) bool {
+	if a.Height != b.Height {
+		return a.Height < b.Height
+	}
+
+	return a.Hash < b.Hash
+}
@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// memoryStore is a Store keeping states in memory
+type memoryStore struct {
+	states map[string]SyncState
+	err    error // Returned by Save, if set
+}
+
+func (s *memoryStore) Load(ctx context.Context, address string) (*SyncState, error) {
+	state, ok := s.states[address]
+	if !ok {
+		return nil, nil
+	}
+
+	return &state, nil
+}
+
+func (s *memoryStore) Save(ctx context.Context, state *SyncState) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.states[state.Address] = *state
+
+	return nil
+}
+
+// newSyncServer serves the transactions in '*txs' from /get_address_txs
+func newSyncServer(t *testing.T, txs *[]Transaction) *httptest.Server {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(GetAddressTxsResponse{
+			ScannedHeight:    3222370,
+			BlockchainHeight: 3222370,
+			Transactions:     *txs,
+		})
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+func TestSyncer(t *testing.T) {
+	txs := []Transaction{
+		{Hash: "aa", Height: 3222300},
+		{Hash: "bb", Height: 3222310},
+		{Hash: "cc", Height: 3222320},
+		{Hash: "dd", Height: 3222371, Mempool: true},
+	}
+
+	ts := newSyncServer(t, &txs)
+	defer ts.Close()
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	store := &memoryStore{states: map[string]SyncState{}}
+	syncer := client.NewSyncer(store)
+
+	if syncer.State() != nil {
+		t.Error("a new Syncer had a state")
+	}
+
+	diff, err := syncer.Refresh(context.Background())
+	if err != nil {
+		t.Fatal("Refresh() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(diff.New, txs) || len(diff.Confirmed)+len(diff.HeightChanged)+len(diff.Removed) != 0 || diff.ScannedHeight != 3222370 {
+		t.Errorf("the first refresh returned: %+v", diff)
+	}
+
+	diff, err = syncer.Refresh(context.Background())
+	if err != nil || !diff.Empty() {
+		t.Errorf("a refresh without changes returned: %+v, %v", diff, err)
+	}
+
+	txs = []Transaction{
+		{Hash: "aa", Height: 3222300},
+		{Hash: "bb", Height: 3222311}, // Reorged
+		{Hash: "dd", Height: 3222372}, // Confirmed
+		{Hash: "ee", Height: 3222373, Mempool: true},
+	}
+
+	// Diffs aren't lost if they can't be saved
+	store.err = errors.New("disk is full")
+
+	_, err = syncer.Refresh(context.Background())
+	if err != store.err {
+		t.Error("Refresh() returned the error: ", err)
+	}
+
+	store.err = nil
+
+	diff, err = syncer.Refresh(context.Background())
+	if err != nil {
+		t.Fatal("Refresh() returned the error: ", err)
+	}
+
+	expected := &SyncDiff{
+		New:              []Transaction{txs[3]},
+		Confirmed:        []Transaction{txs[2]},
+		HeightChanged:    []HeightChange{{Transaction: txs[1], OldHeight: 3222310}},
+		Removed:          []Transaction{{Hash: "cc", Height: 3222320}},
+		ScannedHeight:    3222370,
+		BlockchainHeight: 3222370,
+	}
+
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("Refresh() returned: %+v", diff)
+	}
+
+	state := syncer.State()
+	if len(state.Transactions) != 4 || state.Transactions["dd"].Mempool || state.Address != "xmr_address" {
+		t.Errorf("the Syncer's state was: %+v", state)
+	}
+
+	if saved := store.states["xmr_address"]; !reflect.DeepEqual(&saved, state) {
+		t.Errorf("the saved state was: %+v", saved)
+	}
+}
+
+func TestSyncerReorgAndRescan(t *testing.T) {
+	scanned := uint64(3222370)
+	txs := []Transaction{
+		{Hash: "aa", Height: 3222300},
+		{Hash: "bb", Height: 3222360},
+		{Hash: "cc", Height: 3222365},
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(GetAddressTxsResponse{
+			ScannedHeight:    scanned,
+			BlockchainHeight: 3222370,
+			Transactions:     txs,
+		})
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:   "xmr_address",
+		client:    &http.Client{},
+		serverURL: ts.URL,
+		viewKey:   "xmr_view_key",
+	}
+
+	syncer := client.NewSyncer(&memoryStore{states: map[string]SyncState{}})
+
+	_, err := syncer.Refresh(context.Background())
+	if err != nil {
+		t.Fatal("Refresh() returned the error: ", err)
+	}
+
+	// "cc"'s block was reorged away, so it's back in the mempool
+	txs[2] = Transaction{Hash: "cc", Height: 3222371, Mempool: true}
+
+	diff, err := syncer.Refresh(context.Background())
+	if err != nil {
+		t.Fatal("Refresh() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(diff.Unconfirmed, []Transaction{txs[2]}) || len(diff.New)+len(diff.Confirmed)+len(diff.HeightChanged)+len(diff.Removed) != 0 {
+		t.Errorf("Refresh() returned: %+v", diff)
+	}
+
+	// The server rescans our account, and has only gotten to block 3222350
+	scanned = 3222350
+	txs = []Transaction{{Hash: "aa", Height: 3222300}}
+
+	diff, err = syncer.Refresh(context.Background())
+	if err != nil {
+		t.Fatal("Refresh() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(diff.Removed, []Transaction{{Hash: "cc", Height: 3222371, Mempool: true}}) || len(diff.New)+len(diff.Confirmed)+len(diff.HeightChanged)+len(diff.Unconfirmed) != 0 {
+		t.Errorf("a refresh during a rescan returned: %+v", diff)
+	}
+
+	if _, ok := syncer.State().Transactions["bb"]; !ok {
+		t.Error("a transaction the server hasn't rescanned yet was dropped from the Syncer's state")
+	}
+
+	// The rescan is done, and "bb" is still there
+	scanned = 3222370
+	txs = append(txs, Transaction{Hash: "bb", Height: 3222360})
+
+	diff, err = syncer.Refresh(context.Background())
+	if err != nil || !diff.Empty() {
+		t.Errorf("a refresh after a rescan returned: %+v, %v", diff, err)
+	}
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var ErrorBadCAPEM = errors.New("no certificates could be parsed from ServerTLS.CAPEM")
+
+// ServerTLS holds the TLS settings for one of our servers, for when
+// it uses a self-signed certificate or one from a private CA.
+//
+// If Pins is set without CAPEM, the pin alone authenticates the server,
+// so self-signed certificates can be used, and the server's leaf
+// certificate must match one of Pins. If both are set, the server's
+// certificate chain must be signed by CAPEM and match one of Pins.
+type ServerTLS struct {
+	Pins  []string // Base64 encoded SHA-256 hashes of a SubjectPublicKeyInfo in the server's certificate chain, optionally prefixed with "sha256/"
+	CAPEM []byte   // PEM encoded CA certificates to trust instead of the system's roots
+}
+
+// PinMismatchError is returned (wrapped in an *APIError) when none
+// of the certificates a server presented match its ServerTLS.Pins.
+type PinMismatchError struct {
+	Host string   // The host name of the server
+	Pins []string // The pins of the certificates the server presented
+}
+
+func (e *PinMismatchError) Error() string {
+	return "none of the certificates presented by " + e.Host + " matched its pins (got: " + strings.Join(e.Pins, ", ") + ")"
+}
+
+// SPKIPin returns the pin of 'cert' that can be used in ServerTLS.Pins.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// newTLSConfig returns a copy of 'base' that verifies the server
+// named 'host' using 'settings' instead of the usual verification.
+func newTLSConfig(base *tls.Config, host string, settings ServerTLS) (*tls.Config, error) {
+	var pins [][]byte
+
+	for _, pin := range settings.Pins {
+		b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(pin, "sha256/"))
+		if err != nil {
+			return nil, err
+		}
+
+		pins = append(pins, b)
+	}
+
+	var roots *x509.CertPool
+
+	if len(settings.CAPEM) != 0 {
+		roots = x509.NewCertPool()
+
+		if !roots.AppendCertsFromPEM(settings.CAPEM) {
+			return nil, ErrorBadCAPEM
+		}
+	} else if base != nil {
+		roots = base.RootCAs
+	}
+
+	var cfg *tls.Config
+	if base == nil {
+		cfg = &tls.Config{}
+	} else {
+		cfg = base.Clone()
+	}
+
+	// We verify the server's certificates ourselves in VerifyConnection
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		return verifyConnection(cs, host, roots, pins, len(settings.CAPEM) == 0 && len(pins) != 0)
+	}
+
+	return cfg, nil
+}
+
+// verifyConnection verifies that the certificates in 'cs' belong to
+// 'host' and are signed by 'roots' (nil for the system's roots), then
+// checks that one of them matches 'pins'. If 'pinOnly' is set, only the
+// leaf certificate's pin is checked, so self-signed certificates can be used.
+func verifyConnection(cs tls.ConnectionState, host string, roots *x509.CertPool, pins [][]byte, pinOnly bool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tls: server didn't present a certificate")
+	}
+
+	// Without a verified chain, the other certificates prove nothing: anyone
+	// can send the real server's certificate after their own leaf.
+	chain := cs.PeerCertificates[:1]
+
+	if !pinOnly {
+		opts := x509.VerifyOptions{
+			DNSName:       host,
+			Intermediates: x509.NewCertPool(),
+			Roots:         roots,
+		}
+
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+
+		chains, err := cs.PeerCertificates[0].Verify(opts)
+		if err != nil {
+			return err
+		}
+
+		chain = chains[0]
+	}
+
+	if len(pins) == 0 {
+		return nil
+	}
+
+	var seen []string
+
+	for _, cert := range chain {
+		sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+		for _, pin := range pins {
+			if subtle.ConstantTimeCompare(sum[:], pin) == 1 {
+				return nil
+			}
+		}
+
+		seen = append(seen, base64.StdEncoding.EncodeToString(sum[:]))
+	}
+
+	return &PinMismatchError{Host: host, Pins: seen}
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTLSTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(GetAddressInfoResponse{})
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}))
+}
+
+func TestServerTLS(t *testing.T) {
+	ts := newTLSTestServer(t)
+	defer ts.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+
+	tests := []struct {
+		name     string
+		settings map[string]ServerTLS
+		ok       bool
+	}{
+		{"pinned", map[string]ServerTLS{ts.URL: {Pins: []string{"sha256/" + SPKIPin(ts.Certificate())}}}, true},
+		{"custom CA", map[string]ServerTLS{ts.URL: {CAPEM: caPEM}}, true},
+		{"custom CA and pin", map[string]ServerTLS{ts.URL: {CAPEM: caPEM, Pins: []string{SPKIPin(ts.Certificate())}}}, true},
+		{"no settings", nil, false},
+	}
+
+	for _, test := range tests {
+		client, err := NewClient(Config{
+			Address:   "xmr_address",
+			ServerURL: ts.URL,
+			ServerTLS: test.settings,
+			ViewKey:   "xmr_view_key",
+		})
+		if err != nil {
+			t.Fatalf("%s: NewClient() returned the error: %v", test.name, err)
+		}
+
+		_, err = client.GetAddressInfo()
+		if (err == nil) != test.ok {
+			t.Errorf("%s: GetAddressInfo() returned the error: %v", test.name, err)
+		}
+
+		var unknownAuthority x509.UnknownAuthorityError
+		if !test.ok && !errors.As(err, &unknownAuthority) {
+			t.Errorf("%s: GetAddressInfo() didn't verify the server's certificate: %v", test.name, err)
+		}
+	}
+}
+
+func TestServerTLSPinMismatch(t *testing.T) {
+	ts := newTLSTestServer(t)
+	defer ts.Close()
+
+	other := newTLSTestServer(t)
+	defer other.Close()
+
+	client, err := NewClient(Config{
+		Address:   "xmr_address",
+		ServerURL: ts.URL,
+		ServerTLS: map[string]ServerTLS{ts.URL: {Pins: []string{"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}}},
+		ViewKey:   "xmr_view_key",
+	})
+	if err != nil {
+		t.Fatal("NewClient() returned the error: ", err)
+	}
+
+	_, err = client.GetAddressInfo()
+	if !errors.Is(err, ErrorPostRequestFailed) {
+		t.Error("GetAddressInfo() returned the error: ", err)
+	}
+
+	var pinErr *PinMismatchError
+	if !errors.As(err, &pinErr) {
+		t.Fatal("GetAddressInfo() didn't return a *PinMismatchError: ", err)
+	}
+
+	if len(pinErr.Pins) == 0 || pinErr.Pins[0] != SPKIPin(ts.Certificate()) {
+		t.Errorf("the error didn't hold the server's pin: %v", pinErr)
+	}
+}
+
+func TestServerTLSBadConfig(t *testing.T) {
+	_, err := NewClient(Config{
+		Address:   "xmr_address",
+		ServerTLS: map[string]ServerTLS{"https://api.mymonero.com": {CAPEM: []byte("not a certificate")}},
+		ViewKey:   "xmr_view_key",
+	})
+	if !errors.Is(err, ErrorBadConfig) {
+		t.Error("NewClient() accepted an invalid CA: ", err)
+	}
+
+	_, err = NewClient(Config{
+		Address:   "xmr_address",
+		ServerTLS: map[string]ServerTLS{"https://example.org": {Pins: []string{"47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}}},
+		ViewKey:   "xmr_view_key",
+	})
+	if !errors.Is(err, ErrorBadConfig) {
+		t.Error("NewClient() accepted ServerTLS settings for an unknown server: ", err)
+	}
+}
+
+// newSelfSignedCert creates a self-signed certificate for 'host'
+func newSelfSignedCert(t *testing.T, host string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("failed to generate a key: ", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal("failed to create a certificate: ", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal("failed to parse our certificate: ", err)
+	}
+
+	return cert
+}
+
+func TestServerTLSPinAppendedCert(t *testing.T) {
+	victim := newSelfSignedCert(t, "lws.example.com")
+	attacker := newSelfSignedCert(t, "lws.example.com")
+
+	cfg, err := newTLSConfig(nil, "lws.example.com", ServerTLS{Pins: []string{SPKIPin(victim)}})
+	if err != nil {
+		t.Fatal("newTLSConfig() returned the error: ", err)
+	}
+
+	// An attacker sending the real server's certificate after their own leaf
+	err = cfg.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{attacker, victim}})
+
+	var pinErr *PinMismatchError
+	if !errors.As(err, &pinErr) || len(pinErr.Pins) != 1 || pinErr.Pins[0] != SPKIPin(attacker) {
+		t.Error("VerifyConnection() returned the error: ", err)
+	}
+
+	err = cfg.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{victim, attacker}})
+	if err != nil {
+		t.Error("VerifyConnection() rejected the pinned leaf: ", err)
+	}
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var ErrorBadProxyScheme = errors.New("proxy URL scheme must be socks5 or socks5h")
+
+// newHTTPClient returns the *http.Client our client sends requests with.
+//
+// If cfg.Proxy is set, the returned client uses its own copy of
+// cfg.HTTPClient's transport, so connections are never shared
+// with other clients that use the same proxy.
+func newHTTPClient(cfg *Config) (*http.Client, error) {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	for _, server := range cfg.serverURLs() {
+		u, err := url.Parse(server)
+		if err == nil && strings.HasSuffix(u.Hostname(), ".onion") && cfg.Proxy == "" {
+			cfg.Logger.Error("an .onion server URL was passed to NewClient without a SOCKS5 proxy", "server", server)
+
+			return nil, ErrorBadConfig
+		}
+	}
+
+	if cfg.Proxy == "" {
+		return client, nil
+	}
+
+	proxy, err := proxyURL(cfg.Proxy)
+	if err != nil {
+		cfg.Logger.Error("an invalid proxy URL was passed to NewClient", "error", err)
+
+		return nil, ErrorBadConfig
+	}
+
+	transport, err := cloneTransport(client)
+	if err != nil {
+		cfg.Logger.Error("a proxy can't be used with an HTTPClient whose Transport isn't an *http.Transport")
+
+		return nil, err
+	}
+
+	transport.Proxy = http.ProxyURL(proxy)
+
+	c := *client
+	c.Transport = transport
+
+	return &c, nil
+}
+
+// newServerHTTPClients returns a copy of 'client' for each server in
+// cfg.ServerTLS, keyed by server URL, that verifies the server's
+// certificates using its ServerTLS settings.
+func newServerHTTPClients(cfg *Config, client *http.Client) (map[string]*http.Client, error) {
+	if len(cfg.ServerTLS) == 0 {
+		return nil, nil
+	}
+
+	servers := map[string]bool{}
+	for _, server := range cfg.serverURLs() {
+		servers[server] = true
+	}
+
+	clients := map[string]*http.Client{}
+
+	for server, settings := range cfg.ServerTLS {
+		if !servers[server] {
+			cfg.Logger.Error("ServerTLS settings were passed to NewClient for a server that isn't in ServerURL or ServerURLs", "server", server)
+
+			return nil, ErrorBadConfig
+		}
+
+		u, err := url.Parse(server)
+		if err != nil {
+			cfg.Logger.Error("an invalid server URL was passed to NewClient", "server", server, "error", err)
+
+			return nil, ErrorBadConfig
+		}
+
+		transport, err := cloneTransport(client)
+		if err != nil {
+			cfg.Logger.Error("ServerTLS can't be used with an HTTPClient whose Transport isn't an *http.Transport")
+
+			return nil, err
+		}
+
+		transport.TLSClientConfig, err = newTLSConfig(transport.TLSClientConfig, u.Hostname(), settings)
+		if err != nil {
+			cfg.Logger.Error("invalid ServerTLS settings were passed to NewClient", "server", server, "error", err)
+
+			return nil, ErrorBadConfig
+		}
+
+		c := *client
+		c.Transport = transport
+
+		clients[server] = &c
+	}
+
+	return clients, nil
+}
+
+// cloneTransport returns a copy of the *http.Transport used by 'client'.
+func cloneTransport(client *http.Client) (*http.Transport, error) {
+	switch t := client.Transport.(type) {
+	case nil:
+		return http.DefaultTransport.(*http.Transport).Clone(), nil
+	case *http.Transport:
+		return t.Clone(), nil
+	}
+
+	return nil, ErrorBadConfig
+}
+
+// proxyURL parses a SOCKS5 proxy URL (eg. socks5://127.0.0.1:9050).
+//
+// Unless the URL holds a username and password, random ones are
+// added so (with Tor's default IsolateSOCKSAuth) our requests go
+// over a circuit that isn't shared with any other client.
+func proxyURL(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		// net/http always lets the proxy resolve host names,
+		// which is what "socks5h" asks for elsewhere.
+		u.Scheme = "socks5"
+	default:
+		return nil, &url.Error{Op: "parse", URL: proxy, Err: ErrorBadProxyScheme}
+	}
+
+	if u.User == nil {
+		username, err := randomHex(16)
+		if err != nil {
+			return nil, err
+		}
+
+		password, err := randomHex(16)
+		if err != nil {
+			return nil, err
+		}
+
+		u.User = url.UserPassword(username, password)
+	}
+
+	return u, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// socks5Server is a minimal SOCKS5 proxy that records the credentials
+// and targets of each connection, sending every connection to 'target'.
+type socks5Server struct {
+	listener net.Listener
+	target   string
+
+	mu        sync.Mutex
+	usernames []string
+	hosts     []string
+}
+
+func newSOCKS5Server(t *testing.T, target string) *socks5Server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("failed to listen: ", err)
+	}
+
+	s := &socks5Server{listener: l, target: target}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *socks5Server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	buf := make([]byte, 256)
+
+	// Greeting: version, number of methods, methods
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+
+	if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+		return
+	}
+
+	if _, err := conn.Write([]byte{5, 2}); err != nil { // Username/password authentication
+		return
+	}
+
+	// Authentication: version, username, password
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+
+	username := make([]byte, buf[1])
+	if _, err := io.ReadFull(conn, username); err != nil {
+		return
+	}
+
+	if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+		return
+	}
+
+	if _, err := io.ReadFull(conn, buf[:buf[0]]); err != nil {
+		return
+	}
+
+	if _, err := conn.Write([]byte{1, 0}); err != nil {
+		return
+	}
+
+	// Request: version, command, reserved, address type, address, port
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+		return
+	}
+
+	var host string
+
+	switch buf[3] {
+	case 1:
+		if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+			return
+		}
+
+		host = net.IP(buf[:4]).String()
+	case 3:
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return
+		}
+
+		n := buf[0]
+		if _, err := io.ReadFull(conn, buf[:n]); err != nil {
+			return
+		}
+
+		host = string(buf[:n])
+	default:
+		return
+	}
+
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+
+	host = net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(buf[:2]))))
+
+	s.mu.Lock()
+	s.usernames = append(s.usernames, string(username))
+	s.hosts = append(s.hosts, host)
+	s.mu.Unlock()
+
+	target, err := net.Dial("tcp", s.target)
+	if err != nil {
+		return
+	}
+	defer target.Close()
+
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return
+	}
+
+	go func() {
+		_, _ = io.Copy(target, conn)
+	}()
+
+	_, _ = io.Copy(conn, target)
+}
+
+func TestProxyIsolatesClients(t *testing.T) {
+	const onion = "http://lwsmoneroaddressexampleaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.onion"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := json.NewEncoder(w).Encode(GetAddressInfoResponse{})
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}))
+	defer ts.Close()
+
+	proxy := newSOCKS5Server(t, ts.Listener.Addr().String())
+	defer proxy.listener.Close()
+
+	for _, address := range []string{"xmr_address_1", "xmr_address_2"} {
+		client, err := NewClient(Config{
+			Address:   address,
+			Proxy:     "socks5h://" + proxy.listener.Addr().String(),
+			ServerURL: onion,
+			ViewKey:   "xmr_view_key",
+		})
+		if err != nil {
+			t.Fatal("NewClient() returned the error: ", err)
+		}
+
+		_, err = client.GetAddressInfo()
+		if err != nil {
+			t.Fatal("GetAddressInfo() returned the error: ", err)
+		}
+	}
+
+	proxy.mu.Lock()
+	defer proxy.mu.Unlock()
+
+	if len(proxy.usernames) != 2 || proxy.usernames[0] == proxy.usernames[1] || proxy.usernames[0] == "" {
+		t.Errorf("clients didn't use isolated credentials: %q", proxy.usernames)
+	}
+
+	for _, host := range proxy.hosts {
+		if host != "lwsmoneroaddressexampleaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.onion:80" {
+			t.Error("the proxy wasn't asked to resolve our .onion server, it was asked for ", host)
+		}
+	}
+}
+
+func TestOnionServerNeedsProxy(t *testing.T) {
+	_, err := NewClient(Config{
+		Address:   "xmr_address",
+		ServerURL: "http://lwsmoneroaddressexampleaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.onion",
+		ViewKey:   "xmr_view_key",
+	})
+	if !errors.Is(err, ErrorBadConfig) {
+		t.Error("NewClient() returned the error: ", err)
+	}
+
+	_, err = NewClient(Config{
+		Address: "xmr_address",
+		Proxy:   "http://127.0.0.1:8080",
+		ViewKey: "xmr_view_key",
+	})
+	if !errors.Is(err, ErrorBadConfig) {
+		t.Error("NewClient() accepted a proxy that isn't SOCKS5: ", err)
+	}
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"errors"
+)
+
+// UpsertSubaddrsRequest holds the subaddresses to
+// register with the server in a call to UpsertSubaddrs().
+//
+// It is not required to pass Address or ViewKey, as
+// those are derived from 'client'. Set GetAll to get
+// every subaddress registered for our account back.
+type UpsertSubaddrsRequest struct {
+	Address  string     `json:"address"`
+	ViewKey  string     `json:"view_key" epee:"hex"` // hex encoded binary
+	Subaddrs []Subaddrs `json:"subaddrs"`
+	GetAll   bool       `json:"get_all"`
+}
+
+// UpsertSubaddrsResponse is the response from a call to UpsertSubaddrs().
+//
+// NewSubaddrs holds the ranges that weren't registered
+// before. AllSubaddrs is only sent if GetAll was set.
+type UpsertSubaddrsResponse struct {
+	NewSubaddrs []Subaddrs `json:"new_subaddrs"`
+	AllSubaddrs []Subaddrs `json:"all_subaddrs"`
+}
+
+var ErrorUpsertSubaddrsRequestEncode = errors.New("failed to encode UpsertSubaddrsRequest using data from 'client' and 'request'")
+
+// UpsertSubaddrs registers ranges of subaddresses with the
+// server, so transactions sent to them show up in GetAddressTxs.
+//
+// Ranges that are already registered are left as they are.
+// Servers may limit how many subaddresses an account can have,
+// responding with HTTP 403 (Forbidden) when it's exceeded.
+func (c *Client) UpsertSubaddrs(request *UpsertSubaddrsRequest) (*UpsertSubaddrsResponse, error) {
+	return c.UpsertSubaddrsContext(context.Background(), request)
+}
+
+// UpsertSubaddrsContext is like UpsertSubaddrs but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (c *Client) UpsertSubaddrsContext(ctx context.Context, request *UpsertSubaddrsRequest) (*UpsertSubaddrsResponse, error) {
+	const path = EndpointUpsertSubaddrs
+
+	request.Address = c.address
+	request.ViewKey = c.viewKey
+
+	var response = &UpsertSubaddrsResponse{}
+
+	err := c.post(ctx, path, request, response, ErrorUpsertSubaddrsRequestEncode)
+	if err != nil {
+		return &UpsertSubaddrsResponse{}, err
+	}
+
+	return response, nil
+}
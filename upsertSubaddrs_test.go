@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUpsertSubaddrs(t *testing.T) {
+	tryCount := 1 //Number of times to send HTTP Service Unavailable
+
+	request := &UpsertSubaddrsRequest{
+		Address:  "xmr_address",
+		ViewKey:  "xmr_view_key",
+		Subaddrs: []Subaddrs{{Major: 0, Ranges: []IndexRange{{1, 10}}}},
+		GetAll:   true,
+	}
+
+	response := UpsertSubaddrsResponse{
+		NewSubaddrs: []Subaddrs{{Major: 0, Ranges: []IndexRange{{6, 10}}}},
+		AllSubaddrs: []Subaddrs{{Major: 0, Ranges: []IndexRange{{1, 10}}}},
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req = &UpsertSubaddrsRequest{}
+
+		err := json.NewDecoder(r.Body).Decode(req)
+		if err != nil {
+			t.Error("UpsertSubaddrs() made an invalid request: ", err)
+		}
+
+		if !reflect.DeepEqual(req, request) {
+			t.Error("req struct didn't match the original data in request")
+		}
+
+		if tryCount != 0 {
+			tryCount--
+
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			t.Error("failed to marshal our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	client := &Client{
+		address:    request.Address,
+		client:     &http.Client{},
+		retryCount: tryCount,
+		retryTime:  time.Duration(0),
+		serverURL:  ts.URL,
+		viewKey:    request.ViewKey,
+	}
+
+	resp, err := client.UpsertSubaddrs(&UpsertSubaddrsRequest{
+		Subaddrs: request.Subaddrs,
+		GetAll:   true,
+	})
+	if err != nil {
+		t.Fatal("UpsertSubaddrs() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(*resp, response) {
+		t.Error("response struct didn't match the original data")
+	}
+}
@@ -0,0 +1,321 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// maxWebhookBody is the largest webhook payload a WebhookHandler accepts, in bytes
+const maxWebhookBody = 1 << 20
+
+// WebhookType is the type of event a monero-lws webhook is sent for.
+type WebhookType string
+
+const (
+	WebhookTxConfirmation WebhookType = "tx-confirmation" // An account received XMR, sent once it has enough confirmations
+	WebhookNewAccount     WebhookType = "new-account"     // An account was created
+	WebhookTxSpend        WebhookType = "tx-spend"        // An account's XMR was spent
+)
+
+// AddWebhookRequest describes a webhook for monero-lws to call.
+type AddWebhookRequest struct {
+	Type          WebhookType `json:"type"`
+	Address       string      `json:"address,omitempty"` // The account to watch. Not used for WebhookNewAccount.
+	URL           string      `json:"url"`               // Where events are posted, eg. a WebhookHandler
+	Token         string      `json:"token,omitempty"`   // Sent with every event, so they can be verified
+	PaymentID     string      `json:"payment_id,omitempty"`
+	Confirmations uint32      `json:"confirmations,omitempty"` // For WebhookTxConfirmation, the confirmations to wait for
+}
+
+// Webhook is a webhook registered on a monero-lws server.
+type Webhook struct {
+	EventID       string `json:"event_id"` // A UUID identifying the webhook, see DeleteWebhookEvents
+	PaymentID     string `json:"payment_id"`
+	Token         string `json:"token"`
+	Confirmations uint32 `json:"confirmations"`
+	URL           string `json:"url"`
+}
+
+// WebhookKey identifies the account and event type a set of webhooks is registered for.
+type WebhookKey struct {
+	User uint32      `json:"user"` // The server's ID for the account
+	Type WebhookType `json:"type"`
+}
+
+type WebhookGroup struct {
+	Key   WebhookKey `json:"key"`
+	Value []Webhook  `json:"value"`
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []WebhookGroup `json:"webhooks"`
+}
+
+// AddWebhook registers the webhook described by 'request'.
+func (a *AdminClient) AddWebhook(request *AddWebhookRequest) (*Webhook, error) {
+	return a.AddWebhookContext(context.Background(), request)
+}
+
+// AddWebhookContext is like AddWebhook but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (a *AdminClient) AddWebhookContext(ctx context.Context, request *AddWebhookRequest) (*Webhook, error) {
+	var response = &Webhook{}
+
+	err := a.post(ctx, EndpointWebhookAdd, request, response)
+	if err != nil {
+		return &Webhook{}, err
+	}
+
+	return response, nil
+}
+
+type webhookDeleteParams struct {
+	Addresses []string `json:"addresses"`
+}
+
+// DeleteWebhooks deletes every webhook registered for the accounts in 'addresses'.
+func (a *AdminClient) DeleteWebhooks(addresses []string) error {
+	return a.DeleteWebhooksContext(context.Background(), addresses)
+}
+
+// DeleteWebhooksContext is like DeleteWebhooks but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (a *AdminClient) DeleteWebhooksContext(ctx context.Context, addresses []string) error {
+	return a.post(ctx, EndpointWebhookDelete, &webhookDeleteParams{Addresses: addresses}, nil)
+}
+
+type webhookDeleteUUIDParams struct {
+	EventIDs []string `json:"event_ids"`
+}
+
+// DeleteWebhookEvents deletes the webhooks whose EventIDs are in 'eventIDs'.
+func (a *AdminClient) DeleteWebhookEvents(eventIDs []string) error {
+	return a.DeleteWebhookEventsContext(context.Background(), eventIDs)
+}
+
+// DeleteWebhookEventsContext is like DeleteWebhookEvents but uses 'ctx'
+// to cancel the request or to stop waiting in between retries.
+func (a *AdminClient) DeleteWebhookEventsContext(ctx context.Context, eventIDs []string) error {
+	return a.post(ctx, EndpointWebhookDeleteUUID, &webhookDeleteUUIDParams{EventIDs: eventIDs}, nil)
+}
+
+// ListWebhooks lists every webhook registered on the server.
+func (a *AdminClient) ListWebhooks() (*ListWebhooksResponse, error) {
+	return a.ListWebhooksContext(context.Background())
+}
+
+// ListWebhooksContext is like ListWebhooks but uses 'ctx' to cancel
+// the request or to stop waiting in between retries.
+func (a *AdminClient) ListWebhooksContext(ctx context.Context) (*ListWebhooksResponse, error) {
+	var response = &ListWebhooksResponse{}
+
+	err := a.post(ctx, EndpointWebhookList, &struct{}{}, response)
+	if err != nil {
+		return &ListWebhooksResponse{}, err
+	}
+
+	return response, nil
+}
+
+// WebhookEvent holds the fields every webhook event has.
+type WebhookEvent struct {
+	Event     WebhookType     `json:"event"`
+	EventID   string          `json:"event_id"` // The EventID of the webhook that sent the event
+	Token     string          `json:"token"`
+	PaymentID string          `json:"payment_id"`
+	Raw       json.RawMessage `json:"-"` // The event's full payload, for fields we don't know
+}
+
+// TxConfirmationEvent is sent when an account receives
+// XMR, once it has the webhook's number of confirmations.
+type TxConfirmationEvent struct {
+	WebhookEvent
+
+	Confirmations uint32        `json:"confirmations"`
+	TxInfo        WebhookOutput `json:"tx_info"`
+}
+
+// WebhookOutput is an output received by an account.
+type WebhookOutput struct {
+	Height       uint64 `json:"height"`
+	Index        uint64 `json:"index"` // The output's index in its transaction
+	Amount       Amount `json:"amount"`
+	Timestamp    uint64 `json:"timestamp"`
+	TxHash       string `json:"tx_hash"`        // hex encoded binary
+	TxPrefixHash string `json:"tx_prefix_hash"` // hex encoded binary
+	TxPublicKey  string `json:"tx_public"`      // hex encoded binary
+	PaymentID    string `json:"payment_id"`     // hex encoded binary
+	UnlockTime   uint64 `json:"unlock_time"`
+	Mixin        uint32 `json:"mixin_count"`
+	Coinbase     bool   `json:"coinbase"`
+}
+
+// NewAccountEvent is sent when an account is created.
+type NewAccountEvent struct {
+	WebhookEvent
+
+	Address     string `json:"address"`
+	StartHeight uint64 `json:"start_height"`
+}
+
+// TxSpendEvent is sent when an account's XMR is spent.
+type TxSpendEvent struct {
+	WebhookEvent
+
+	TxInfo WebhookSpend `json:"tx_info"`
+}
+
+// WebhookSpend is a spend of one of an account's outputs.
+type WebhookSpend struct {
+	Input struct {
+		Height     uint64 `json:"height"`
+		TxHash     string `json:"tx_hash"` // hex encoded binary
+		KeyImage   string `json:"image"`   // hex encoded binary
+		UnlockTime uint64 `json:"unlock_time"`
+		Timestamp  uint64 `json:"timestamp"`
+		Mixin      uint32 `json:"mixin_count"`
+	} `json:"input"`
+	Source WebhookOutput `json:"source"` // The output that was spent
+}
+
+// WebhookHandler is an http.Handler receiving monero-lws webhook
+// events (eg. at the URL passed to AdminClient.AddWebhook). Events
+// without the handler's token are rejected. Others are passed to
+// the callbacks registered for their type.
+//
+// Callbacks are called with the request's context. If one returns
+// an error, the server is sent HTTP 500 so it can retry the event.
+type WebhookHandler struct {
+	token string
+
+	mu             sync.RWMutex
+	txConfirmation []func(context.Context, *TxConfirmationEvent) error
+	newAccount     []func(context.Context, *NewAccountEvent) error
+	txSpend        []func(context.Context, *TxSpendEvent) error
+}
+
+// NewWebhookHandler creates a WebhookHandler accepting events sent with
+// 'token' (see AddWebhookRequest.Token). 'token' can't be empty.
+func NewWebhookHandler(token string) *WebhookHandler {
+	return &WebhookHandler{token: token}
+}
+
+// OnTxConfirmation registers 'fn' to be called with every tx-confirmation event.
+func (h *WebhookHandler) OnTxConfirmation(fn func(context.Context, *TxConfirmationEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.txConfirmation = append(h.txConfirmation, fn)
+}
+
+// OnNewAccount registers 'fn' to be called with every new-account event.
+func (h *WebhookHandler) OnNewAccount(fn func(context.Context, *NewAccountEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.newAccount = append(h.newAccount, fn)
+}
+
+// OnTxSpend registers 'fn' to be called with every tx-spend event.
+func (h *WebhookHandler) OnTxSpend(fn func(context.Context, *TxSpendEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.txSpend = append(h.txSpend, fn)
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody+1))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if len(b) > maxWebhookBody {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+
+		return
+	}
+
+	var event WebhookEvent
+
+	err = json.Unmarshal(b, &event)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if h.token == "" || subtle.ConstantTimeCompare([]byte(event.Token), []byte(h.token)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	event.Raw = b
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	switch event.Event {
+	case WebhookTxConfirmation:
+		err = dispatch(r.Context(), b, event, h.txConfirmation, func(e *TxConfirmationEvent) *WebhookEvent { return &e.WebhookEvent })
+	case WebhookNewAccount:
+		err = dispatch(r.Context(), b, event, h.newAccount, func(e *NewAccountEvent) *WebhookEvent { return &e.WebhookEvent })
+	case WebhookTxSpend:
+		err = dispatch(r.Context(), b, event, h.txSpend, func(e *TxSpendEvent) *WebhookEvent { return &e.WebhookEvent })
+	}
+
+	switch err {
+	case nil:
+		w.WriteHeader(http.StatusOK)
+	case errWebhookMalformed:
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// errWebhookMalformed is returned by dispatch when an event can't be decoded
+var errWebhookMalformed = errors.New("webhook event is malformed")
+
+// dispatch decodes the event in 'b' and passes it to each of 'callbacks',
+// stopping at the first error. 'common' returns the event's WebhookEvent.
+func dispatch[T any](ctx context.Context, b []byte, event WebhookEvent, callbacks []func(context.Context, *T) error, common func(*T) *WebhookEvent) error {
+	if len(callbacks) == 0 {
+		return nil
+	}
+
+	e := new(T)
+
+	err := json.Unmarshal(b, e)
+	if err != nil {
+		return errWebhookMalformed
+	}
+
+	*common(e) = event
+
+	for _, fn := range callbacks {
+		err = fn(ctx, e)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
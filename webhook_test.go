@@ -0,0 +1,192 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright © 2024 Christian Hering
+
+package gomonerolight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWebhookAdmin(t *testing.T) {
+	params := map[Endpoint]string{}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Auth   string          `json:"auth"`
+			Params json.RawMessage `json:"params"`
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Error("AdminClient made an invalid request: ", err)
+		}
+
+		params[Endpoint(r.URL.Path)] = string(req.Params)
+
+		var response string
+
+		switch Endpoint(r.URL.Path) {
+		case EndpointWebhookAdd:
+			response = `{"event_id":"c5a735e71b1e4f0a8bfaa2b6a6a4f0d1","payment_id":"","token":"webhook_token","confirmations":10,"url":"https://example.com/hook"}`
+		case EndpointWebhookList:
+			response = `{"webhooks":[{"key":{"user":1,"type":"tx-confirmation"},"value":[{"event_id":"c5a735e71b1e4f0a8bfaa2b6a6a4f0d1","payment_id":"","token":"webhook_token","confirmations":10,"url":"https://example.com/hook"}]}]}`
+		case EndpointWebhookDelete, EndpointWebhookDeleteUUID:
+		default:
+			t.Error("AdminClient called ", r.URL.Path)
+		}
+
+		_, err = w.Write([]byte(response))
+		if err != nil {
+			t.Error("failed to write our response")
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	admin, err := NewAdminClient(Config{ServerURL: ts.URL}, "admin_auth_key")
+	if err != nil {
+		t.Fatal("NewAdminClient() returned the error: ", err)
+	}
+
+	webhook := Webhook{
+		EventID:       "c5a735e71b1e4f0a8bfaa2b6a6a4f0d1",
+		Token:         "webhook_token",
+		Confirmations: 10,
+		URL:           "https://example.com/hook",
+	}
+
+	added, err := admin.AddWebhook(&AddWebhookRequest{
+		Type:          WebhookTxConfirmation,
+		Address:       "xmr_address",
+		URL:           webhook.URL,
+		Token:         webhook.Token,
+		Confirmations: webhook.Confirmations,
+	})
+	if err != nil {
+		t.Fatal("AddWebhook() returned the error: ", err)
+	}
+
+	if !reflect.DeepEqual(*added, webhook) {
+		t.Error("AddWebhook() returned: ", added)
+	}
+
+	list, err := admin.ListWebhooks()
+	if err != nil {
+		t.Fatal("ListWebhooks() returned the error: ", err)
+	}
+
+	expected := ListWebhooksResponse{Webhooks: []WebhookGroup{{Key: WebhookKey{User: 1, Type: WebhookTxConfirmation}, Value: []Webhook{webhook}}}}
+
+	if !reflect.DeepEqual(*list, expected) {
+		t.Error("ListWebhooks() returned: ", list)
+	}
+
+	err = admin.DeleteWebhooks([]string{"xmr_address"})
+	if err != nil {
+		t.Fatal("DeleteWebhooks() returned the error: ", err)
+	}
+
+	err = admin.DeleteWebhookEvents([]string{webhook.EventID})
+	if err != nil {
+		t.Fatal("DeleteWebhookEvents() returned the error: ", err)
+	}
+
+	expectedParams := map[Endpoint]string{
+		EndpointWebhookAdd:        `{"type":"tx-confirmation","address":"xmr_address","url":"https://example.com/hook","token":"webhook_token","confirmations":10}`,
+		EndpointWebhookList:       `{}`,
+		EndpointWebhookDelete:     `{"addresses":["xmr_address"]}`,
+		EndpointWebhookDeleteUUID: `{"event_ids":["c5a735e71b1e4f0a8bfaa2b6a6a4f0d1"]}`,
+	}
+
+	if !reflect.DeepEqual(params, expectedParams) {
+		t.Error("AdminClient sent the params: ", params)
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	const (
+		confirmation = `{"event":"tx-confirmation","payment_id":"","token":"webhook_token","confirmations":10,"event_id":"c5a735e7","tx_info":{"height":3222360,"index":1,"amount":314159,"timestamp":1718000000,"tx_hash":"aa","tx_prefix_hash":"bb","tx_public":"cc","payment_id":"","unlock_time":0,"mixin_count":15,"coinbase":false}}`
+		newAccount   = `{"event":"new-account","event_id":"d6b846f8","token":"webhook_token","address":"xmr_address","start_height":3222000}`
+		spend        = `{"event":"tx-spend","event_id":"e7c957a9","token":"webhook_token","tx_info":{"input":{"height":3222370,"tx_hash":"dd","image":"ee","unlock_time":0,"timestamp":1718000120,"mixin_count":15},"source":{"height":3222360,"index":1,"amount":314159,"tx_hash":"aa"}}}`
+	)
+
+	h := NewWebhookHandler("webhook_token")
+
+	var confirmations []*TxConfirmationEvent
+	var accounts []*NewAccountEvent
+	var spends []*TxSpendEvent
+
+	h.OnTxConfirmation(func(ctx context.Context, e *TxConfirmationEvent) error {
+		confirmations = append(confirmations, e)
+
+		return nil
+	})
+
+	h.OnNewAccount(func(ctx context.Context, e *NewAccountEvent) error {
+		accounts = append(accounts, e)
+
+		if e.Address == "xmr_failing" {
+			return errors.New("database is down")
+		}
+
+		return nil
+	})
+
+	h.OnTxSpend(func(ctx context.Context, e *TxSpendEvent) error {
+		spends = append(spends, e)
+
+		return nil
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+	}{
+		{"tx-confirmation", http.MethodPost, confirmation, http.StatusOK},
+		{"new-account", http.MethodPost, newAccount, http.StatusOK},
+		{"tx-spend", http.MethodPost, spend, http.StatusOK},
+		{"unknown event", http.MethodPost, `{"event":"tx-reorg","token":"webhook_token"}`, http.StatusOK},
+		{"wrong token", http.MethodPost, strings.Replace(confirmation, "webhook_token", "guessed_token", 1), http.StatusUnauthorized},
+		{"no token", http.MethodPost, `{"event":"new-account","address":"xmr_address"}`, http.StatusUnauthorized},
+		{"malformed", http.MethodPost, `{"event":`, http.StatusBadRequest},
+		{"wrong type", http.MethodPost, `{"event":"tx-spend","token":"webhook_token","tx_info":{"input":{"height":"high"}}}`, http.StatusBadRequest},
+		{"callback failed", http.MethodPost, strings.Replace(newAccount, "xmr_address", "xmr_failing", 1), http.StatusInternalServerError},
+		{"GET", http.MethodGet, "", http.StatusMethodNotAllowed},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, httptest.NewRequest(test.method, "/webhook", strings.NewReader(test.body)))
+
+		if w.Code != test.status {
+			t.Errorf("%s: the handler responded with HTTP %d, expected %d", test.name, w.Code, test.status)
+		}
+	}
+
+	if len(confirmations) != 1 || confirmations[0].Confirmations != 10 || confirmations[0].TxInfo.Amount != 314159 || confirmations[0].EventID != "c5a735e7" {
+		t.Errorf("OnTxConfirmation() callback got: %+v", confirmations)
+	}
+
+	if len(confirmations) == 1 && string(confirmations[0].Raw) != confirmation {
+		t.Error("TxConfirmationEvent.Raw was ", string(confirmations[0].Raw))
+	}
+
+	if len(accounts) != 2 || accounts[0].Address != "xmr_address" || accounts[0].StartHeight != 3222000 {
+		t.Errorf("OnNewAccount() callback got: %+v", accounts)
+	}
+
+	if len(spends) != 1 || spends[0].TxInfo.Input.KeyImage != "ee" || spends[0].TxInfo.Source.Amount != 314159 {
+		t.Errorf("OnTxSpend() callback got: %+v", spends)
+	}
+}